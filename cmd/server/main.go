@@ -49,18 +49,63 @@ func main(){
 	hubRepo := storage.NewHubRepo(cluster)
 	skuRepo := storage.NewSKURepo(cluster)
 	inventoryRepo := storage.NewInventoryRepo(cluster)
+	reservationRepo := storage.NewReservationRepo(cluster)
+	movementRepo := storage.NewMovementRepo(cluster)
+	transferRepo := storage.NewTransferRepo(cluster)
+	serialRepo := storage.NewSerialRepo(cluster)
+	cycleCountRepo := storage.NewCycleCountRepo(cluster)
+	inboundRepo := storage.NewInboundRepo(cluster)
+	allocationRepo := storage.NewAllocationRepo(cluster)
+	pickingRepo := storage.NewPickingRepo(cluster)
+	shipmentRepo := storage.NewShipmentRepo(cluster)
+	returnRepo := storage.NewReturnRepo(cluster)
+	idempotencyRepo := storage.NewIdempotencyRepo(cluster)
+	importRepo := storage.NewImportRepo(cluster)
 
 	//services
-	hubService := services.NewHubService(hubRepo)
+	hubService := services.NewHubService(hubRepo, inventoryRepo, skuRepo)
 	skuService := services.NewSKUService(skuRepo)
-	inventoryService := services.NewInventoryService(inventoryRepo, skuRepo, hubRepo)
+	inventoryService := services.NewInventoryService(inventoryRepo, skuRepo, hubRepo, movementRepo)
+	reservationService := services.NewReservationService(reservationRepo, skuRepo, hubRepo, cfg.Reservation.DefaultTTL)
+	transferService := services.NewTransferService(transferRepo, skuRepo, hubRepo)
+	locationService := services.NewLocationService(hubRepo)
+	serialService := services.NewSerialService(serialRepo)
+	cycleCountService := services.NewCycleCountService(cycleCountRepo, skuRepo, hubRepo, cfg.CycleCount.RecountThresholdPct)
+	inboundService := services.NewInboundService(inboundRepo, skuRepo, hubRepo)
+	putawayService := services.NewPutawayService(inventoryRepo, hubRepo, skuRepo)
+	allocationService := services.NewAllocationService(allocationRepo, inventoryRepo, skuRepo, hubRepo, cfg.Allocation.SplitPolicy, cfg.Allocation.HoldTTL)
+	pickingService := services.NewPickingService(pickingRepo, hubRepo, cfg.Allocation.HoldTTL)
+	shipmentService := services.NewShipmentService(shipmentRepo, allocationRepo, skuRepo, hubRepo)
+	returnService := services.NewReturnService(returnRepo, skuRepo, hubRepo)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.PendingTimeout)
+	skuImportService := services.NewSKUImportService(importRepo, hubRepo, cfg.Import.MaxRows, cfg.Import.LeaseTimeout)
+	replenishmentService := services.NewReplenishmentService(movementRepo, hubRepo, cfg.Replenishment)
 
 	//handlers
 	hubHandler := handlers.NewHubHandler(hubService)
 	skuHandler := handlers.NewSKUHandler(skuService)
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
-
-	setup.SetupRoutes(server, hubHandler, skuHandler, inventoryHandler)
+	reservationHandler := handlers.NewReservationHandler(reservationService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	locationHandler := handlers.NewLocationHandler(locationService)
+	serialHandler := handlers.NewSerialHandler(serialService)
+	cycleCountHandler := handlers.NewCycleCountHandler(cycleCountService)
+	inboundHandler := handlers.NewInboundHandler(inboundService)
+	putawayHandler := handlers.NewPutawayHandler(putawayService)
+	allocationHandler := handlers.NewAllocationHandler(allocationService)
+	pickingHandler := handlers.NewPickingHandler(pickingService)
+	shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+	returnHandler := handlers.NewReturnHandler(returnService)
+	idempotencyHandler := handlers.NewIdempotencyHandler(idempotencyService)
+	skuImportHandler := handlers.NewSKUImportHandler(skuImportService)
+	replenishmentHandler := handlers.NewReplenishmentHandler(replenishmentService)
+
+	setup.SetupRoutes(server, hubHandler, skuHandler, inventoryHandler, reservationHandler, transferHandler, locationHandler, serialHandler, cycleCountHandler, inboundHandler, putawayHandler, allocationHandler, pickingHandler, shipmentHandler, returnHandler, idempotencyHandler, skuImportHandler, replenishmentHandler)
+
+	//background workers
+	go reservationService.StartExpiryWorker(ctx, cfg.Reservation.ExpiryInterval)
+	go idempotencyService.StartCleanupWorker(ctx, cfg.Idempotency.CleanupInterval)
+	go skuImportService.StartWorker(ctx, cfg.Import.PollInterval)
 
 	log.InfofWithContext(ctx, "starting server on port 3001")
 	if err := server.StartServer("wms-service"); err != nil {
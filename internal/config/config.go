@@ -61,6 +61,32 @@ func InitConfig(ctx context.Context) {
 				Endpoint: config.GetString(ctx, "aws.endpoint"),
 			},
 		},
+		Reservation: types.ReservationConfig{
+			DefaultTTL:     config.GetDuration(ctx, "reservation.default_ttl"),
+			ExpiryInterval: config.GetDuration(ctx, "reservation.expiry_interval"),
+		},
+		CycleCount: types.CycleCountConfig{
+			RecountThresholdPct: config.GetInt(ctx, "cycle_count.recount_threshold_pct"),
+		},
+		Allocation: types.AllocationConfig{
+			SplitPolicy: config.GetString(ctx, "allocation.split_policy"),
+			HoldTTL:     config.GetDuration(ctx, "allocation.hold_ttl"),
+		},
+		Idempotency: types.IdempotencyConfig{
+			TTL:             config.GetDuration(ctx, "idempotency.ttl"),
+			CleanupInterval: config.GetDuration(ctx, "idempotency.cleanup_interval"),
+			PendingTimeout:  config.GetDuration(ctx, "idempotency.pending_timeout"),
+		},
+		Import: types.ImportConfig{
+			MaxRows:      config.GetInt(ctx, "import.max_rows"),
+			PollInterval: config.GetDuration(ctx, "import.poll_interval"),
+			LeaseTimeout: config.GetDuration(ctx, "import.lease_timeout"),
+		},
+		Replenishment: types.ReplenishmentConfig{
+			WindowDays:      config.GetInt(ctx, "replenishment.window_days"),
+			LeadTimeDays:    config.GetInt(ctx, "replenishment.lead_time_days"),
+			SafetyStockDays: config.GetInt(ctx, "replenishment.safety_stock_days"),
+		},
 	}
 }
 func loadSlavesConfig(ctx context.Context) []postgres.DBConfig {
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type AllocationHandler struct {
+	AllocationService *services.AllocationService
+}
+
+func NewAllocationHandler(allocationService *services.AllocationService) *AllocationHandler {
+	return &AllocationHandler{
+		AllocationService: allocationService,
+	}
+}
+
+func (h *AllocationHandler) AllocateOrder(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[AllocationHandler][AllocateOrder]"
+	log.InfofWithContext(ctx, logTag+" allocating order")
+
+	var body struct {
+		TenantID         string `json:"tenant_id" validate:"required"`
+		SellerID         string `json:"seller_id" validate:"required"`
+		OrderRef         string `json:"order_ref" validate:"required,min=1,max=100"`
+		SplitPolicy      string `json:"split_policy,omitempty" validate:"omitempty,oneof=none line unit"`
+		DeliveryLocation struct {
+			Latitude  *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+			Longitude *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+		} `json:"delivery_location" validate:"required"`
+		Actor string            `json:"actor,omitempty"`
+		Lines []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	allocation, err := h.AllocationService.AllocateOrder(ctx, body.TenantID, body.SellerID, body.OrderRef, body.SplitPolicy, *body.DeliveryLocation.Latitude, *body.DeliveryLocation.Longitude, toSKUQuantities(body.Lines), storage.MovementInfo{
+		Actor: body.Actor,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to allocate order %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" order allocated successfully")
+	utils.SuccessReponse(c, http.StatusCreated, allocation)
+}
+
+func (h *AllocationHandler) GetAllocation(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[AllocationHandler][GetAllocation]"
+	log.InfofWithContext(ctx, logTag+" getting order allocation")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	allocation, err := h.AllocationService.GetAllocation(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get order allocation %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, allocation)
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type CycleCountHandler struct {
+	CycleCountService *services.CycleCountService
+}
+
+func NewCycleCountHandler(cycleCountService *services.CycleCountService) *CycleCountHandler {
+	return &CycleCountHandler{
+		CycleCountService: cycleCountService,
+	}
+}
+
+func (h *CycleCountHandler) CreateCycleCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[CycleCountHandler][CreateCycleCount]"
+	log.InfofWithContext(ctx, logTag+" creating cycle count")
+
+	var body struct {
+		TenantID    string   `json:"tenant_id" validate:"required"`
+		SellerID    string   `json:"seller_id" validate:"required"`
+		HubID       int      `json:"hub_id" validate:"required,min=1"`
+		SKUCodes    []string `json:"sku_codes,omitempty" validate:"omitempty,max=500,dive,required,min=1"`
+		LocationIDs []int    `json:"location_ids,omitempty" validate:"omitempty,max=500,dive,min=1"`
+		Actor       string   `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	count, err := h.CycleCountService.CreateCycleCount(ctx, body.TenantID, body.SellerID, body.HubID, body.SKUCodes, body.LocationIDs, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create cycle count %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" cycle count created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, count)
+}
+
+func (h *CycleCountHandler) GetCycleCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[CycleCountHandler][GetCycleCount]"
+	log.InfofWithContext(ctx, logTag+" getting cycle count")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	count, err := h.CycleCountService.GetCycleCount(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get cycle count %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, count)
+}
+
+func (h *CycleCountHandler) SubmitCounts(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[CycleCountHandler][SubmitCounts]"
+	log.InfofWithContext(ctx, logTag+" submitting cycle count quantities")
+
+	type countedLine struct {
+		LineID   int64  `json:"line_id" validate:"required,min=1"`
+		Quantity *int64 `json:"quantity" validate:"required,min=0"`
+	}
+
+	var body struct {
+		TenantID string        `json:"tenant_id" validate:"required"`
+		ID       int64         `json:"id" validate:"required,min=1"`
+		Actor    string        `json:"actor,omitempty"`
+		Lines    []countedLine `json:"lines" validate:"required,min=1,max=1000,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	counted := make(map[int64]int64, len(body.Lines))
+	for _, line := range body.Lines {
+		counted[line.LineID] = *line.Quantity
+	}
+
+	count, err := h.CycleCountService.SubmitCounts(ctx, body.TenantID, body.ID, counted, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to submit counts %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, count)
+}
+
+func (h *CycleCountHandler) ApproveCycleCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[CycleCountHandler][ApproveCycleCount]"
+	log.InfofWithContext(ctx, logTag+" approving cycle count")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor" validate:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	count, err := h.CycleCountService.ApproveCycleCount(ctx, body.TenantID, body.ID, body.Actor, storage.MovementInfo{})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to approve cycle count %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, count)
+}
+
+func (h *CycleCountHandler) CancelCycleCount(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[CycleCountHandler][CancelCycleCount]"
+	log.InfofWithContext(ctx, logTag+" cancelling cycle count")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	count, err := h.CycleCountService.CancelCycleCount(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel cycle count %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, count)
+}
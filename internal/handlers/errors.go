@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/omniful/go_commons/http"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+)
+
+// errorStatus maps storage sentinel errors to the http status returned to the caller
+func errorStatus(err error) http.StatusCode {
+	switch {
+	case errors.Is(err, storage.ErrInventoryNotFound),
+		errors.Is(err, storage.ErrReservationNotFound),
+		errors.Is(err, storage.ErrTransferNotFound),
+		errors.Is(err, storage.ErrHubNotFound),
+		errors.Is(err, storage.ErrLocationNotFound),
+		errors.Is(err, storage.ErrSerialNotFound),
+		errors.Is(err, storage.ErrCycleCountNotFound),
+		errors.Is(err, storage.ErrInboundNotFound),
+		errors.Is(err, storage.ErrAllocationNotFound),
+		errors.Is(err, storage.ErrWaveNotFound),
+		errors.Is(err, storage.ErrPickTaskNotFound),
+		errors.Is(err, storage.ErrShipmentNotFound),
+		errors.Is(err, storage.ErrCartonNotFound),
+		errors.Is(err, storage.ErrReturnNotFound),
+		errors.Is(err, storage.ErrImportJobNotFound),
+		errors.Is(err, storage.ErrSKUNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrInsufficientStock),
+		errors.Is(err, storage.ErrReservationExists),
+		errors.Is(err, storage.ErrReservationNotHeld),
+		errors.Is(err, storage.ErrReservationExpired),
+		errors.Is(err, storage.ErrInvalidTransition),
+		errors.Is(err, storage.ErrQuantityExceeded),
+		errors.Is(err, storage.ErrLocationInUse),
+		errors.Is(err, storage.ErrSerialConflict),
+		errors.Is(err, storage.ErrInboundExists),
+		errors.Is(err, storage.ErrAllocationExists),
+		errors.Is(err, storage.ErrReturnExists),
+		errors.Is(err, storage.ErrIdempotencyKeyInUse),
+		errors.Is(err, storage.ErrVersionConflict),
+		errors.Is(err, storage.ErrHubInactive),
+		errors.Is(err, storage.ErrHubInUse),
+		errors.Is(err, storage.ErrSKUArchived),
+		errors.Is(err, storage.ErrSKUInUse),
+		errors.Is(err, storage.ErrSKUExists):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrSerialRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, storage.ErrIdempotencyMismatch):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
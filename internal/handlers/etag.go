@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inventoryETag is the ETag of an inventory row at version
+func inventoryETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// expectedVersion is the row version a writer expects, taken from the
+// If-Match header or the expected_version field of the body. nil means the
+// write goes through whatever the current version is
+func expectedVersion(c *gin.Context, bodyVersion *int64) (*int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return bodyVersion, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || version < 0 {
+		return nil, fmt.Errorf("If-Match must be an inventory ETag, got %s", header)
+	}
+	if bodyVersion != nil && *bodyVersion != version {
+		return nil, fmt.Errorf("If-Match %s does not match expected_version %d", header, *bodyVersion)
+	}
+
+	return &version, nil
+}
@@ -55,9 +55,13 @@ func (h *HubHandler) CreateHub(c *gin.Context) {
 
 	hub, err := h.HubService.CreateHub(ctx, body.TenantId, body.Name, datatypes.JSON(body.Location))
 	if err != nil {
-		log.ErrorfWithContext(ctx, logTag+" failed to get hub: %v", err)
-		c.JSON(http.StatusNotFound.Code(), gin.H{
-			"error": "Hub not found",
+		log.ErrorfWithContext(ctx, logTag+" failed to create hub: %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
 		})
 		return
 	}
@@ -116,6 +120,8 @@ func (h *HubHandler) GetHub(c *gin.Context) {
         "tenant_id":  hub.TenantID,
         "name":       hub.Name,
         "location":   location,
+        "attributes": hub.Attributes,
+        "active":     hub.Active,
         "created_at": hub.CreatedAt,
     }
 
@@ -143,6 +149,7 @@ func (h *HubHandler) GetAllHubs(c *gin.Context) {
 			"TenantID": hub.TenantID,
 			"Name":     hub.Name,
 			"Location": hub.Location,
+			"Active":   hub.Active,
 		})
 	}
 
@@ -151,3 +158,191 @@ func (h *HubHandler) GetAllHubs(c *gin.Context) {
 		"count": len(response),
 	})
 }
+
+// GetNearestHubs lists the hubs of the tenant closest to a point, optionally
+// only those able to ship a list of skus in full
+func (h *HubHandler) GetNearestHubs(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[HubHandler][GetNearestHubs]"
+	log.InfofWithContext(ctx, logTag+" finding nearest hubs")
+
+	var body struct {
+		TenantID  string            `json:"tenant_id" validate:"required"`
+		SellerID  string            `json:"seller_id" validate:"required_with=Lines"`
+		Latitude  *float64          `json:"latitude" validate:"required,min=-90,max=90"`
+		Longitude *float64          `json:"longitude" validate:"required,min=-180,max=180"`
+		RadiusKm  float64           `json:"radius_km,omitempty" validate:"omitempty,gt=0"`
+		Limit     int               `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+		Lines     []skuQuantityLine `json:"lines,omitempty" validate:"omitempty,max=100,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind json%v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	hubs, err := h.HubService.GetNearestHubs(ctx, body.TenantID, body.SellerID, *body.Latitude, *body.Longitude, body.RadiusKm, toSKUQuantities(body.Lines), body.Limit)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to find nearest hubs %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"items": hubs,
+		"count": len(hubs),
+	})
+}
+
+func (h *HubHandler) UpdateHub(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[HubHandler][UpdateHub]"
+	log.InfofWithContext(ctx, logTag+" updating hub")
+
+	var body struct {
+		TenantID   string         `json:"tenant_id" validate:"required"`
+		ID         int            `json:"id" validate:"required,min=1"`
+		Name       *string        `json:"name,omitempty" validate:"omitempty,min=1"`
+		Location   datatypes.JSON `json:"location,omitempty"`
+		Attributes datatypes.JSON `json:"attributes,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind json%v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	hub, err := h.HubService.UpdateHub(ctx, body.TenantID, body.ID, body.Name, body.Location, body.Attributes)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to update hub %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, hub)
+}
+
+func (h *HubHandler) DeactivateHub(c *gin.Context) {
+	h.setHubActive(c, "[HubHandler][DeactivateHub]", false)
+}
+
+func (h *HubHandler) ActivateHub(c *gin.Context) {
+	h.setHubActive(c, "[HubHandler][ActivateHub]", true)
+}
+
+func (h *HubHandler) setHubActive(c *gin.Context, logTag string, active bool) {
+	ctx := c.Request.Context()
+	log.InfofWithContext(ctx, logTag+" setting hub active to %t", active)
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int    `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind json%v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	hub, err := h.HubService.SetHubActive(ctx, body.TenantID, body.ID, active)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to set hub active flag %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, hub)
+}
+
+// DeleteHub removes a hub, refused while it holds stock in any condition
+// bucket, has held reservations or appears on any order
+func (h *HubHandler) DeleteHub(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[HubHandler][DeleteHub]"
+	log.InfofWithContext(ctx, logTag+" deleting hub")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int    `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind json%v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	if err := h.HubService.DeleteHub(ctx, body.TenantID, body.ID); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to delete hub %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"id":      body.ID,
+		"deleted": true,
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+)
+
+// IdempotencyKeyHeader names the header clients put their retry key in
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// replayedHeaders are the response headers stored with a key and sent again
+// on replays
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+type IdempotencyHandler struct {
+	IdempotencyService *services.IdempotencyService
+}
+
+func NewIdempotencyHandler(idempotencyService *services.IdempotencyService) *IdempotencyHandler {
+	return &IdempotencyHandler{
+		IdempotencyService: idempotencyService,
+	}
+}
+
+// Handle is a middleware for mutating routes. A POST or PATCH carrying an
+// Idempotency-Key runs once per tenant and route, retries with the same key
+// get the stored response back
+func (h *IdempotencyHandler) Handle(c *gin.Context) {
+	key := c.GetHeader(IdempotencyKeyHeader)
+	if key == "" || (c.Request.Method != "POST" && c.Request.Method != "PATCH") {
+		c.Next()
+		return
+	}
+
+	ctx := c.Request.Context()
+	logTag := "[IdempotencyHandler][Handle]"
+
+	if len(key) > 255 {
+		c.AbortWithStatusJSON(http.StatusBadRequest.Code(), gin.H{
+			"error": IdempotencyKeyHeader + " must be at most 255 characters",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to read request body %v", err)
+		c.AbortWithStatusJSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	// the tenant comes from the body like everywhere else, a body without one
+	// shares the empty tenant
+	var tenant struct {
+		TenantID string `json:"tenant_id"`
+	}
+	_ = json.Unmarshal(body, &tenant)
+
+	scope := storage.IdempotencyScope{
+		TenantID: tenant.TenantID,
+		Method:   c.Request.Method,
+		Path:     c.FullPath(),
+		Key:      key,
+	}
+
+	stored, err := h.IdempotencyService.Begin(ctx, scope, body)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to claim idempotency key %v", err)
+		c.AbortWithStatusJSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if stored != nil {
+		contentType := "application/json; charset=utf-8"
+		for name, value := range stored.ResponseHeaders {
+			if v, ok := value.(string); ok {
+				c.Header(name, v)
+			}
+		}
+		if v, ok := stored.ResponseHeaders["Content-Type"].(string); ok {
+			contentType = v
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.ResponseCode, contentType, stored.ResponseBody)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	headers := make(map[string]string)
+	for _, name := range replayedHeaders {
+		if value := recorder.Header().Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	// the response is already written, a client hanging up now must not
+	// keep it from being stored
+	if err := h.IdempotencyService.Finish(context.WithoutCancel(ctx), scope, recorder.Status(), headers, recorder.body.Bytes()); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to store idempotent response %v", err)
+	}
+}
+
+// responseRecorder keeps a copy of the response body written by the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
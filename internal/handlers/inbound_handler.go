@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type InboundHandler struct {
+	InboundService *services.InboundService
+}
+
+func NewInboundHandler(inboundService *services.InboundService) *InboundHandler {
+	return &InboundHandler{
+		InboundService: inboundService,
+	}
+}
+
+func (h *InboundHandler) CreateInbound(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InboundHandler][CreateInbound]"
+	log.InfofWithContext(ctx, logTag+" creating inbound order")
+
+	var body struct {
+		TenantID   string            `json:"tenant_id" validate:"required"`
+		SellerID   string            `json:"seller_id" validate:"required"`
+		HubID      int               `json:"hub_id" validate:"required,min=1"`
+		Type       string            `json:"type" validate:"required,oneof=purchase_order asn"`
+		Reference  string            `json:"reference" validate:"required,min=1,max=100"`
+		ExpectedAt *time.Time        `json:"expected_at,omitempty"`
+		Lines      []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	order, err := h.InboundService.CreateInbound(ctx, body.TenantID, body.SellerID, body.HubID, body.Type, body.Reference, body.ExpectedAt, toSKUQuantities(body.Lines))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create inbound order %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" inbound order created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, order)
+}
+
+func (h *InboundHandler) GetInbound(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InboundHandler][GetInbound]"
+	log.InfofWithContext(ctx, logTag+" getting inbound order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	order, err := h.InboundService.GetInbound(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get inbound order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, inboundResponse(order))
+}
+
+func (h *InboundHandler) ReceiveInbound(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InboundHandler][ReceiveInbound]"
+	log.InfofWithContext(ctx, logTag+" receiving inbound order")
+
+	var body struct {
+		TenantID string            `json:"tenant_id" validate:"required"`
+		ID       int64             `json:"id" validate:"required,min=1"`
+		Actor    string            `json:"actor,omitempty"`
+		Lines    []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	order, err := h.InboundService.ReceiveInbound(ctx, body.TenantID, body.ID, toSKUQuantities(body.Lines), storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive inbound order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, inboundResponse(order))
+}
+
+func (h *InboundHandler) CloseInbound(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InboundHandler][CloseInbound]"
+	log.InfofWithContext(ctx, logTag+" closing inbound order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	order, err := h.InboundService.CloseInbound(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to close inbound order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, inboundResponse(order))
+}
+
+func (h *InboundHandler) CancelInbound(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InboundHandler][CancelInbound]"
+	log.InfofWithContext(ctx, logTag+" cancelling inbound order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	order, err := h.InboundService.CancelInbound(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel inbound order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, order)
+}
+
+// inboundResponse adds the discrepancy report and its totals to an order
+func inboundResponse(order *models.InboundOrder) gin.H {
+	report := order.Discrepancies()
+
+	var short, over int64
+	for _, line := range report {
+		switch line.Result {
+		case models.DiscrepancyShort:
+			short -= line.Variance
+		case models.DiscrepancyOver:
+			over += line.Variance
+		}
+	}
+
+	return gin.H{
+		"inbound_order": order,
+		"discrepancies": report,
+		"short_units":   short,
+		"over_units":    over,
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+// exportFlushEvery is how many rows are written between flushes
+const exportFlushEvery = 500
+
+var inventoryExportColumns = []string{"tenant_id", "seller_id", "hub_id", "sku_id", "sku_code", "sku_name", "on_hand", "sellable", "damaged", "quarantine", "expired", "reserved", "expired_lots", "available", "version", "updated_at"}
+
+// inventoryExportWriter writes export rows to the response as they come,
+// headers go out with the first row so an early error can still be sent as
+// json
+type inventoryExportWriter struct {
+	c       *gin.Context
+	format  string
+	started bool
+	rows    int
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+func newInventoryExportWriter(c *gin.Context, format string) *inventoryExportWriter {
+	if format == "" {
+		format = "csv"
+	}
+	return &inventoryExportWriter{
+		c:      c,
+		format: format,
+	}
+}
+
+func (w *inventoryExportWriter) start() error {
+	w.started = true
+
+	name := fmt.Sprintf("inventory_%s.%s", time.Now().UTC().Format("20060102T150405Z"), w.format)
+	w.c.Header("Content-Disposition", "attachment; filename="+name)
+	if w.format == "ndjson" {
+		w.c.Header("Content-Type", "application/x-ndjson")
+		w.c.Status(http.StatusOK.Code())
+		w.json = json.NewEncoder(w.c.Writer)
+		return nil
+	}
+
+	w.c.Header("Content-Type", "text/csv")
+	w.c.Status(http.StatusOK.Code())
+	w.csv = csv.NewWriter(w.c.Writer)
+	return w.csv.Write(inventoryExportColumns)
+}
+
+func (w *inventoryExportWriter) write(row *models.InventoryExportRow) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if w.json != nil {
+		err = w.json.Encode(row)
+	} else {
+		err = w.csv.Write([]string{
+			row.TenantID,
+			row.SellerID,
+			strconv.Itoa(row.HubID),
+			strconv.Itoa(row.SKUID),
+			row.SKUCode,
+			row.SKUName,
+			strconv.FormatInt(row.OnHand, 10),
+			strconv.FormatInt(row.Sellable, 10),
+			strconv.FormatInt(row.Damaged, 10),
+			strconv.FormatInt(row.Quarantine, 10),
+			strconv.FormatInt(row.Expired, 10),
+			strconv.FormatInt(row.Reserved, 10),
+			strconv.FormatInt(row.ExpiredLots, 10),
+			strconv.FormatInt(row.Available, 10),
+			strconv.FormatInt(row.Version, 10),
+			row.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushEvery == 0 {
+		w.flush()
+	}
+	return nil
+}
+
+// finish writes out whatever is buffered, an export without rows still gets
+// its csv header
+func (w *inventoryExportWriter) finish() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	w.flush()
+	if w.csv != nil {
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func (w *inventoryExportWriter) flush() {
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	w.c.Writer.Flush()
+}
@@ -1,13 +1,14 @@
 package handlers
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omniful/go_commons/http"
 	"github.com/omniful/go_commons/log"
 	"github.com/omniful/go_commons/validator"
 	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
 	"github.com/singhJasvinder101/go_wms/utils"
 )
 
@@ -28,11 +29,14 @@ func (h *InventoryHandler) CreateInventory(c *gin.Context) {
     log.InfofWithContext(ctx, logTag+" creating inventory")
 
 	var body struct {
-		TenantID string `json:"tenant_id" validate:"required"`
-		SellerID string `json:"seller_id" validate:"required"`
-		SKUCode  string `json:"sku_code" validate:"required,min=1"`
-		HubID    int    `json:"hub_id" validate:"required,min=1"`
-		Quantity int64  `json:"quantity" validate:"required,min=0"`
+		TenantID  string `json:"tenant_id" validate:"required"`
+		SellerID  string `json:"seller_id" validate:"required"`
+		SKUCode   string `json:"sku_code" validate:"required,min=1"`
+		HubID     int    `json:"hub_id" validate:"required,min=1"`
+		Quantity  int64  `json:"quantity" validate:"required,min=0"`
+		Actor     string `json:"actor,omitempty"`
+		Reference string `json:"reference,omitempty"`
+		Serials   []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
 	}
 
     if err := c.ShouldBindJSON(&body); err != nil {
@@ -52,11 +56,19 @@ func (h *InventoryHandler) CreateInventory(c *gin.Context) {
         return
 	}
 
-    inventory, err := h.InventoryService.CreateInventory(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Quantity)
+    inventory, err := h.InventoryService.CreateInventory(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Quantity, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
     if err != nil {
         log.ErrorfWithContext(ctx, logTag+" failed to create inventory: %v", err)
-        c.JSON(http.StatusInternalServerError.Code(), gin.H{
-            "error": "Failed to create inventory",
+        status := errorStatus(err)
+        if status == http.StatusInternalServerError {
+            status = http.StatusBadRequest
+        }
+        c.JSON(status.Code(), gin.H{
+            "error": err.Error(),
         })
         return
     }
@@ -79,11 +91,15 @@ func (h *InventoryHandler) UpsertInventory(c *gin.Context) {
     log.InfofWithContext(ctx, logTag+" upserting inventory")
 
     var body struct {
-		TenantID string `json:"tenant_id" validate:"required"`
-		SellerID string `json:"seller_id" validate:"required"`
-		SKUCode  string `json:"sku_code" validate:"required,min=1"`
-		HubID    int    `json:"hub_id" validate:"required,min=1"`
-		Quantity int64  `json:"quantity" validate:"required,min=0"`
+		TenantID  string `json:"tenant_id" validate:"required"`
+		SellerID  string `json:"seller_id" validate:"required"`
+		SKUCode   string `json:"sku_code" validate:"required,min=1"`
+		HubID     int    `json:"hub_id" validate:"required,min=1"`
+		Quantity  int64  `json:"quantity" validate:"required,min=0"`
+		Actor     string `json:"actor,omitempty"`
+		Reference string `json:"reference,omitempty"`
+		Serials   []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+		ExpectedVersion *int64 `json:"expected_version,omitempty" validate:"omitempty,min=0"`
 	}
     if err := c.ShouldBindJSON(&body); err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
@@ -99,12 +115,31 @@ func (h *InventoryHandler) UpsertInventory(c *gin.Context) {
 			"error":  err.ErrorMessage(),
 			"errors": err.ErrorMap(),
 		})
+		return
 	}
 
+	version, err := expectedVersion(c, body.ExpectedVersion)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" invalid expected version %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
-    inventory, err := h.InventoryService.UpsertInventory(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Quantity)
+    inventory, err := h.InventoryService.UpsertInventory(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Quantity, version, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
     if err != nil {
         log.ErrorfWithContext(ctx, logTag+" failed to upsert inventory %v", err)
+        if status := errorStatus(err); status != http.StatusInternalServerError {
+            c.JSON(status.Code(), gin.H{
+                "error": err.Error(),
+            })
+            return
+        }
         c.JSON(http.StatusInternalServerError.Code(), gin.H{
             "error": "Failed to upsert inventory",
         })
@@ -116,9 +151,11 @@ func (h *InventoryHandler) UpsertInventory(c *gin.Context) {
         "SKUID":    inventory.SKUID,
         "HubID":    inventory.HubID,
         "Quantity": inventory.Quantity,
+        "Version":  inventory.Version,
     }
 
     log.InfofWithContext(ctx, logTag+" inventory upserted successfully")
+    c.Header("ETag", inventoryETag(inventory.Version))
     c.JSON(http.StatusOK.Code(), response)
 }
 
@@ -148,6 +185,7 @@ func (h *InventoryHandler) GetInventory(c *gin.Context) {
 			"error":  err.ErrorMessage(),
 			"errors": err.ErrorMap(),
 		})
+		return
 	}
 
     inventoryList, err := h.InventoryService.InventoryRepo.GetByHubAndSeller(ctx, body.HubID, body.SellerID)
@@ -191,6 +229,7 @@ func (h *InventoryHandler) GetInventoryBySKUs(c *gin.Context) {
 			"error":  err.ErrorMessage(),
 			"errors": err.ErrorMap(),
 		})
+		return
 	}
 
     inventoryList, err := h.InventoryService.InventoryRepo.GetByHubSellerSKUs(ctx, body.HubID, body.SellerID, body.SKUCodes)
@@ -206,9 +245,57 @@ func (h *InventoryHandler) GetInventoryBySKUs(c *gin.Context) {
         "items": inventoryList,
         "count": len(inventoryList),
     }
+    if len(inventoryList) == 1 {
+        c.Header("ETag", inventoryETag(inventoryList[0].Version))
+    }
     utils.SuccessReponse(c, http.StatusOK, response)
 }
 
+// GetNetworkATP returns the available to promise quantity of a seller's skus
+// across every active hub of the tenant
+func (h *InventoryHandler) GetNetworkATP(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InventoryHandler][GetNetworkATP]"
+	log.InfofWithContext(ctx, logTag+" getting network ATP")
+
+	var body struct {
+		TenantID string   `json:"tenant_id" validate:"required"`
+		SellerID string   `json:"seller_id" validate:"required"`
+		SKUCodes []string `json:"sku_codes" validate:"required,min=1,max=100,unique,dive,required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	atp, err := h.InventoryService.GetNetworkATP(ctx, body.TenantID, body.SellerID, body.SKUCodes)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get network ATP %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"items": atp,
+		"count": len(atp),
+	})
+}
+
 func (h *InventoryHandler) UpdateInventoryQuantity(c *gin.Context) {
     ctx := c.Request.Context()
     logTag := "[InventoryHandler][UpdateInventoryQuantity]"
@@ -219,6 +306,11 @@ func (h *InventoryHandler) UpdateInventoryQuantity(c *gin.Context) {
 		SellerID string `json:"seller_id" validate:"required"`
 		SkuID  int `json:"sku_id" validate:"required,min=1"`
 		Quantity int    `json:"quantity" validate:"required"`
+		Reason   string `json:"reason,omitempty" validate:"omitempty,oneof=adjustment sale return correction shrinkage"`
+		Actor    string `json:"actor,omitempty"`
+		Reference string `json:"reference,omitempty"`
+		Serials   []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+		ExpectedVersion *int64 `json:"expected_version,omitempty" validate:"omitempty,min=0"`
 	}
     if err := c.ShouldBindJSON(&body); err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
@@ -234,14 +326,29 @@ func (h *InventoryHandler) UpdateInventoryQuantity(c *gin.Context) {
 			"error":  err.ErrorMessage(),
 			"errors": err.ErrorMap(),
 		})
+		return
 	}
 
-	fmt.Println("here is body", body)
-    err := h.InventoryService.UpdateInventoryQuantity(ctx, body.HubID, body.SellerID, body.SkuID, body.Quantity)
+	version, err := expectedVersion(c, body.ExpectedVersion)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" invalid expected version %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+    inventory, movement, err := h.InventoryService.UpdateInventoryQuantity(ctx, body.HubID, body.SellerID, body.SkuID, body.Quantity, version, storage.MovementInfo{
+        Reason:    body.Reason,
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
     if err != nil {
         log.ErrorfWithContext(ctx, logTag+" failed to update inventory quantity %v", err)
-        c.JSON(http.StatusInternalServerError.Code(), gin.H{
+        c.JSON(errorStatus(err).Code(), gin.H{
             "error": "Failed to update inventory quantity",
+            "message": err.Error(),
         })
         return
     }
@@ -254,8 +361,500 @@ func (h *InventoryHandler) UpdateInventoryQuantity(c *gin.Context) {
         "hub_id": body.HubID,
         "seller_id": body.SellerID,
         "quantity_change": body.Quantity,
+        "quantity_before": movement.QuantityBefore,
+        "quantity_after": movement.QuantityAfter,
+        "movement_id": movement.ID,
+        "version": inventory.Version,
     }
 
+    c.Header("ETag", inventoryETag(inventory.Version))
 
     utils.SuccessReponse(c, http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+func (h *InventoryHandler) AdjustInventoryBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InventoryHandler][AdjustInventoryBatch]"
+	log.InfofWithContext(ctx, logTag+" applying batch adjustment")
+
+	var body struct {
+		TenantID      string `json:"tenant_id" validate:"required"`
+		AllowNegative bool   `json:"allow_negative,omitempty"`
+		Actor         string `json:"actor,omitempty"`
+		Reference     string `json:"reference,omitempty"`
+		Lines         []struct {
+			HubID    int      `json:"hub_id" validate:"required,min=1"`
+			SellerID string   `json:"seller_id" validate:"required"`
+			SKUCode  string   `json:"sku_code" validate:"required,min=1"`
+			Delta    int64    `json:"delta" validate:"required"`
+			Reason   string   `json:"reason,omitempty" validate:"omitempty,oneof=adjustment sale return correction shrinkage"`
+			Serials  []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+		} `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	lines := make([]services.InventoryAdjustment, 0, len(body.Lines))
+	for _, line := range body.Lines {
+		lines = append(lines, services.InventoryAdjustment{
+			HubID:    line.HubID,
+			SellerID: line.SellerID,
+			SKUCode:  line.SKUCode,
+			Delta:    line.Delta,
+			Reason:   line.Reason,
+			Serials:  line.Serials,
+		})
+	}
+
+	results, err := h.InventoryService.AdjustInventoryBatch(ctx, body.TenantID, lines, body.AllowNegative, storage.MovementInfo{
+		Actor:     body.Actor,
+		Reference: body.Reference,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to apply batch adjustment %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" batch adjustment applied successfully")
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"lines": results,
+		"count": len(results),
+	})
+}
+
+// ExportInventory streams the inventory of a tenant as csv or ndjson, the
+// response is written while rows are read so an error after the first row
+// can only cut the stream short
+func (h *InventoryHandler) ExportInventory(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[InventoryHandler][ExportInventory]"
+	log.InfofWithContext(ctx, logTag+" exporting inventory")
+
+	var query struct {
+		TenantID string `form:"tenant_id" validate:"required"`
+		HubID    int    `form:"hub_id" validate:"omitempty,min=1"`
+		SellerID string `form:"seller_id"`
+		Format   string `form:"format" validate:"omitempty,oneof=csv ndjson"`
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind query %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, query); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	writer := newInventoryExportWriter(c, query.Format)
+	err := h.InventoryService.ExportInventory(ctx, storage.InventoryExportFilter{
+		TenantID: query.TenantID,
+		HubID:    query.HubID,
+		SellerID: query.SellerID,
+	}, writer.write)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to export inventory %v", err)
+		if !writer.started {
+			c.JSON(errorStatus(err).Code(), gin.H{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	if err := writer.finish(); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to write inventory export %v", err)
+	}
+}
+
+func (h *InventoryHandler) GetMovements(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][GetMovements]"
+    log.InfofWithContext(ctx, logTag+" getting inventory movements")
+
+    var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		SellerID string `json:"seller_id" validate:"required"`
+		HubID    int    `json:"hub_id" validate:"required,min=1"`
+		SKUCode  string `json:"sku_code" validate:"required,min=1"`
+		Page     int    `json:"page,omitempty" validate:"omitempty,min=1"`
+		PageSize int    `json:"page_size,omitempty" validate:"omitempty,min=1,max=200"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    if body.Page == 0 {
+        body.Page = 1
+    }
+    if body.PageSize == 0 {
+        body.PageSize = 50
+    }
+
+    movements, total, err := h.InventoryService.GetMovements(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Page, body.PageSize)
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to get movements: %v", err)
+        c.JSON(http.StatusInternalServerError.Code(), gin.H{
+            "error": "Failed to fetch inventory movements",
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, gin.H{
+        "items":     movements,
+        "count":     len(movements),
+        "total":     total,
+        "page":      body.Page,
+        "page_size": body.PageSize,
+    })
+}
+
+func (h *InventoryHandler) GetInventoryAsOf(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][GetInventoryAsOf]"
+    log.InfofWithContext(ctx, logTag+" getting inventory as of timestamp")
+
+    var body struct {
+		TenantID string    `json:"tenant_id" validate:"required"`
+		SellerID string    `json:"seller_id" validate:"required"`
+		HubID    int       `json:"hub_id" validate:"required,min=1"`
+		SKUCodes []string  `json:"sku_codes,omitempty" validate:"omitempty,min=1,max=100,dive,required,min=1"`
+		AsOf     time.Time `json:"as_of" validate:"required"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    if body.AsOf.After(time.Now()) {
+        c.JSON(http.StatusBadRequest.Code(), gin.H{
+            "error": "as_of cannot be in the future",
+        })
+        return
+    }
+
+    snapshots, err := h.InventoryService.GetInventoryAsOf(ctx, body.HubID, body.SellerID, body.SKUCodes, body.AsOf)
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to get inventory as of timestamp: %v", err)
+        c.JSON(http.StatusInternalServerError.Code(), gin.H{
+            "error": "Failed to fetch inventory",
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, gin.H{
+        "as_of": body.AsOf,
+        "items": snapshots,
+        "count": len(snapshots),
+    })
+}
+
+func (h *InventoryHandler) GetBinStock(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][GetBinStock]"
+    log.InfofWithContext(ctx, logTag+" getting bin stock")
+
+    var body struct {
+		TenantID   string   `json:"tenant_id" validate:"required"`
+		SellerID   string   `json:"seller_id" validate:"required"`
+		HubID      int      `json:"hub_id" validate:"required,min=1"`
+		SKUCodes   []string `json:"sku_codes,omitempty" validate:"omitempty,min=1,max=100,dive,required,min=1"`
+		LocationID int      `json:"location_id,omitempty" validate:"omitempty,min=1"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    stock, err := h.InventoryService.GetBinStock(ctx, body.HubID, body.SellerID, body.SKUCodes, body.LocationID)
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to get bin stock: %v", err)
+        c.JSON(http.StatusInternalServerError.Code(), gin.H{
+            "error": "Failed to fetch bin stock",
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, gin.H{
+        "items": stock,
+        "count": len(stock),
+    })
+}
+
+func (h *InventoryHandler) AdjustBinStock(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][AdjustBinStock]"
+    log.InfofWithContext(ctx, logTag+" adjusting bin stock")
+
+    var body struct {
+		TenantID   string `json:"tenant_id" validate:"required"`
+		SellerID   string `json:"seller_id" validate:"required"`
+		HubID      int    `json:"hub_id" validate:"required,min=1"`
+		SKUCode    string `json:"sku_code" validate:"required,min=1"`
+		LocationID int    `json:"location_id" validate:"required,min=1"`
+		Quantity   int64  `json:"quantity" validate:"required"`
+		Actor      string `json:"actor,omitempty"`
+		Reference  string `json:"reference,omitempty"`
+		Serials    []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    movement, err := h.InventoryService.AdjustBinStock(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.LocationID, body.Quantity, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to adjust bin stock: %v", err)
+        c.JSON(errorStatus(err).Code(), gin.H{
+            "error": err.Error(),
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, movement)
+}
+
+func (h *InventoryHandler) MoveBinStock(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][MoveBinStock]"
+    log.InfofWithContext(ctx, logTag+" moving bin stock")
+
+    var body struct {
+		TenantID       string `json:"tenant_id" validate:"required"`
+		SellerID       string `json:"seller_id" validate:"required"`
+		HubID          int    `json:"hub_id" validate:"required,min=1"`
+		SKUCode        string `json:"sku_code" validate:"required,min=1"`
+		FromLocationID *int   `json:"from_location_id,omitempty" validate:"required_without=ToLocationID,omitempty,min=1"`
+		ToLocationID   *int   `json:"to_location_id,omitempty" validate:"required_without=FromLocationID,omitempty,min=1"`
+		Quantity       int64  `json:"quantity" validate:"required,min=1"`
+		Actor          string `json:"actor,omitempty"`
+		Reference      string `json:"reference,omitempty"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    movement, err := h.InventoryService.MoveBinStock(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.FromLocationID, body.ToLocationID, body.Quantity, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+    })
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to move bin stock: %v", err)
+        c.JSON(errorStatus(err).Code(), gin.H{
+            "error": err.Error(),
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, movement)
+}
+
+func (h *InventoryHandler) ReceiveLot(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][ReceiveLot]"
+    log.InfofWithContext(ctx, logTag+" receiving lot")
+
+    var body struct {
+		TenantID       string     `json:"tenant_id" validate:"required"`
+		SellerID       string     `json:"seller_id" validate:"required"`
+		HubID          int        `json:"hub_id" validate:"required,min=1"`
+		SKUCode        string     `json:"sku_code" validate:"required,min=1"`
+		LotNumber      string     `json:"lot_number" validate:"required,min=1,max=100"`
+		ManufacturedAt *time.Time `json:"manufactured_at,omitempty"`
+		ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+		Quantity       int64      `json:"quantity" validate:"required,min=1"`
+		Actor          string     `json:"actor,omitempty"`
+		Reference      string     `json:"reference,omitempty"`
+		Serials        []string   `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    lot, movement, err := h.InventoryService.ReceiveLot(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.LotNumber, body.ManufacturedAt, body.ExpiresAt, body.Quantity, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to receive lot: %v", err)
+        status := errorStatus(err)
+        if status == http.StatusInternalServerError {
+            status = http.StatusBadRequest
+        }
+        c.JSON(status.Code(), gin.H{
+            "error": err.Error(),
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusCreated, gin.H{
+        "lot":      lot,
+        "movement": movement,
+    })
+}
+
+func (h *InventoryHandler) MoveInventoryStatus(c *gin.Context) {
+    ctx := c.Request.Context()
+    logTag := "[InventoryHandler][MoveInventoryStatus]"
+    log.InfofWithContext(ctx, logTag+" moving inventory between status buckets")
+
+    var body struct {
+		TenantID   string   `json:"tenant_id" validate:"required"`
+		SellerID   string   `json:"seller_id" validate:"required"`
+		HubID      int      `json:"hub_id" validate:"required,min=1"`
+		SKUCode    string   `json:"sku_code" validate:"required,min=1"`
+		FromStatus string   `json:"from_status" validate:"required,oneof=sellable damaged quarantine expired"`
+		ToStatus   string   `json:"to_status" validate:"required,oneof=sellable damaged quarantine expired,nefield=FromStatus"`
+		Quantity   int64    `json:"quantity" validate:"required,min=1"`
+		Actor      string   `json:"actor,omitempty"`
+		Reference  string   `json:"reference,omitempty"`
+		Serials    []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+    if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" plaes enter vlaid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+    inventory, movement, err := h.InventoryService.MoveInventoryStatus(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.FromStatus, body.ToStatus, body.Quantity, storage.MovementInfo{
+        Actor:     body.Actor,
+        Reference: body.Reference,
+        Serials:   body.Serials,
+    })
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to move inventory status: %v", err)
+        c.JSON(errorStatus(err).Code(), gin.H{
+            "error": err.Error(),
+        })
+        return
+    }
+
+    utils.SuccessReponse(c, http.StatusOK, gin.H{
+        "inventory": inventory,
+        "movement":  movement,
+    })
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import "github.com/singhJasvinder101/go_wms/internal/services"
+
+// skuQuantityLine is the request shape for a sku code and quantity pair
+type skuQuantityLine struct {
+	SKUCode  string   `json:"sku_code" validate:"required,min=1"`
+	Quantity int64    `json:"quantity" validate:"required,min=1"`
+	Serials  []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+}
+
+func toSKUQuantities(lines []skuQuantityLine) []services.SKUQuantity {
+	quantities := make([]services.SKUQuantity, 0, len(lines))
+	for _, line := range lines {
+		quantities = append(quantities, services.SKUQuantity{
+			SKUCode:  line.SKUCode,
+			Quantity: line.Quantity,
+			Serials:  line.Serials,
+		})
+	}
+	return quantities
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
+	"gorm.io/datatypes"
+)
+
+type LocationHandler struct {
+	LocationService *services.LocationService
+}
+
+func NewLocationHandler(locationService *services.LocationService) *LocationHandler {
+	return &LocationHandler{
+		LocationService: locationService,
+	}
+}
+
+// locationURI is bound from /hubs/:id/locations/:location_id
+type locationURI struct {
+	HubID      int `uri:"id" validate:"required,min=1"`
+	LocationID int `uri:"location_id"`
+}
+
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[LocationHandler][CreateLocation]"
+	log.InfofWithContext(ctx, logTag+" creating hub location")
+
+	var uri locationURI
+	var body struct {
+		ParentID     *int           `json:"parent_id,omitempty" validate:"omitempty,min=1"`
+		Type         string         `json:"type" validate:"required,oneof=zone aisle rack bin"`
+		Code         string         `json:"code" validate:"required,min=1,max=50"`
+		Name         string         `json:"name,omitempty" validate:"omitempty,max=200"`
+		Capacity     *int64         `json:"capacity,omitempty" validate:"omitempty,min=0"`
+		Attributes   datatypes.JSON `json:"attributes,omitempty"`
+		WalkSequence *int           `json:"walk_sequence,omitempty" validate:"omitempty,min=0"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	location, err := h.LocationService.CreateLocation(ctx, uri.HubID, body.ParentID, body.Type, body.Code, body.Name, body.Capacity, body.WalkSequence, body.Attributes)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create location %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusCreated, location)
+}
+
+func (h *LocationHandler) GetLocations(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[LocationHandler][GetLocations]"
+	log.InfofWithContext(ctx, logTag+" getting hub locations")
+
+	var uri locationURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	locations, err := h.LocationService.GetLocations(ctx, uri.HubID, c.Query("type"))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get locations %v", err)
+		c.JSON(http.StatusInternalServerError.Code(), gin.H{
+			"error": "Failed to fetch locations",
+		})
+		return
+	}
+
+	if locations == nil {
+		locations = []models.HubLocation{}
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"locations": locations,
+		"count":     len(locations),
+	})
+}
+
+func (h *LocationHandler) GetLocation(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[LocationHandler][GetLocation]"
+	log.InfofWithContext(ctx, logTag+" getting hub location")
+
+	var uri locationURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	location, err := h.LocationService.GetLocation(ctx, uri.HubID, uri.LocationID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get location %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, location)
+}
+
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[LocationHandler][UpdateLocation]"
+	log.InfofWithContext(ctx, logTag+" updating hub location")
+
+	var uri locationURI
+	var body struct {
+		Code         *string        `json:"code,omitempty" validate:"omitempty,min=1,max=50"`
+		Name         *string        `json:"name,omitempty" validate:"omitempty,max=200"`
+		Capacity     *int64         `json:"capacity,omitempty" validate:"omitempty,min=0"`
+		Attributes   datatypes.JSON `json:"attributes,omitempty"`
+		WalkSequence *int           `json:"walk_sequence,omitempty" validate:"omitempty,min=0"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	location, err := h.LocationService.UpdateLocation(ctx, uri.HubID, uri.LocationID, body.Code, body.Name, body.Capacity, body.WalkSequence, body.Attributes)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to update location %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, location)
+}
+
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[LocationHandler][DeleteLocation]"
+	log.InfofWithContext(ctx, logTag+" deleting hub location")
+
+	var uri locationURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.LocationService.DeleteLocation(ctx, uri.HubID, uri.LocationID); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to delete location %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"id":      uri.LocationID,
+		"deleted": true,
+	})
+}
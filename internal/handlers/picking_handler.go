@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type PickingHandler struct {
+	PickingService *services.PickingService
+}
+
+func NewPickingHandler(pickingService *services.PickingService) *PickingHandler {
+	return &PickingHandler{
+		PickingService: pickingService,
+	}
+}
+
+func (h *PickingHandler) CreateWave(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PickingHandler][CreateWave]"
+	log.InfofWithContext(ctx, logTag+" creating pick wave")
+
+	var body struct {
+		TenantID      string  `json:"tenant_id" validate:"required"`
+		HubID         int     `json:"hub_id" validate:"required,min=1"`
+		AllocationIDs []int64 `json:"allocation_ids,omitempty" validate:"omitempty,max=500,dive,min=1"`
+		MaxOrders     int     `json:"max_orders,omitempty" validate:"omitempty,min=1,max=500"`
+		Actor         string  `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	wave, err := h.PickingService.CreateWave(ctx, body.TenantID, body.HubID, body.AllocationIDs, body.MaxOrders, storage.MovementInfo{
+		Actor: body.Actor,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create pick wave %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" pick wave created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, wave)
+}
+
+func (h *PickingHandler) GetWave(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PickingHandler][GetWave]"
+	log.InfofWithContext(ctx, logTag+" getting pick wave")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	wave, err := h.PickingService.GetWave(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get pick wave %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, wave)
+}
+
+func (h *PickingHandler) ConfirmPick(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PickingHandler][ConfirmPick]"
+	log.InfofWithContext(ctx, logTag+" confirming pick task")
+
+	var body struct {
+		TenantID  string   `json:"tenant_id" validate:"required"`
+		TaskID    int64    `json:"task_id" validate:"required,min=1"`
+		Picked    *int64   `json:"picked_quantity" validate:"required,min=0"`
+		Actor     string   `json:"actor,omitempty"`
+		Reference string   `json:"reference,omitempty"`
+		Serials   []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	task, err := h.PickingService.ConfirmPick(ctx, body.TenantID, body.TaskID, *body.Picked, storage.MovementInfo{
+		Actor:     body.Actor,
+		Reference: body.Reference,
+		Serials:   body.Serials,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to confirm pick %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, task)
+}
+
+func (h *PickingHandler) CancelWave(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PickingHandler][CancelWave]"
+	log.InfofWithContext(ctx, logTag+" cancelling pick wave")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	wave, err := h.PickingService.CancelWave(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel pick wave %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, wave)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type PutawayHandler struct {
+	PutawayService *services.PutawayService
+}
+
+func NewPutawayHandler(putawayService *services.PutawayService) *PutawayHandler {
+	return &PutawayHandler{
+		PutawayService: putawayService,
+	}
+}
+
+func (h *PutawayHandler) SuggestPutaway(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PutawayHandler][SuggestPutaway]"
+	log.InfofWithContext(ctx, logTag+" suggesting putaway bins")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		SellerID string `json:"seller_id" validate:"required"`
+		HubID    int    `json:"hub_id" validate:"required,min=1"`
+		SKUCode  string `json:"sku_code" validate:"required,min=1"`
+		Quantity int64  `json:"quantity" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	plan, err := h.PutawayService.SuggestPutaway(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.Quantity)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to suggest putaway %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, plan)
+}
+
+func (h *PutawayHandler) ConfirmPutaway(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[PutawayHandler][ConfirmPutaway]"
+	log.InfofWithContext(ctx, logTag+" confirming putaway")
+
+	var body struct {
+		TenantID   string `json:"tenant_id" validate:"required"`
+		SellerID   string `json:"seller_id" validate:"required"`
+		HubID      int    `json:"hub_id" validate:"required,min=1"`
+		SKUCode    string `json:"sku_code" validate:"required,min=1"`
+		LocationID int    `json:"location_id" validate:"required,min=1"`
+		Quantity   int64  `json:"quantity" validate:"required,min=1"`
+		Actor      string `json:"actor,omitempty"`
+		Reference  string `json:"reference,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	movement, err := h.PutawayService.ConfirmPutaway(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.LocationID, body.Quantity, storage.MovementInfo{
+		Actor:     body.Actor,
+		Reference: body.Reference,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to confirm putaway %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, movement)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type ReplenishmentHandler struct {
+	ReplenishmentService *services.ReplenishmentService
+}
+
+func NewReplenishmentHandler(replenishmentService *services.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		ReplenishmentService: replenishmentService,
+	}
+}
+
+func (h *ReplenishmentHandler) GetReplenishmentReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReplenishmentHandler][GetReplenishmentReport]"
+	log.InfofWithContext(ctx, logTag+" getting replenishment report")
+
+	var body struct {
+		TenantID        string   `json:"tenant_id" validate:"required"`
+		HubID           int      `json:"hub_id,omitempty" validate:"omitempty,min=1"`
+		SellerID        string   `json:"seller_id,omitempty"`
+		SKUCodes        []string `json:"sku_codes,omitempty" validate:"omitempty,max=100,dive,required,min=1"`
+		WindowDays      int      `json:"window_days,omitempty" validate:"omitempty,min=1,max=365"`
+		LeadTimeDays    int      `json:"lead_time_days,omitempty" validate:"omitempty,min=1,max=365"`
+		SafetyStockDays int      `json:"safety_stock_days,omitempty" validate:"omitempty,min=1,max=365"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	report, err := h.ReplenishmentService.GetReplenishmentReport(ctx, storage.ReplenishmentFilter{
+		TenantID: body.TenantID,
+		HubID:    body.HubID,
+		SellerID: body.SellerID,
+		SKUCodes: body.SKUCodes,
+	}, body.WindowDays, body.LeadTimeDays, body.SafetyStockDays)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get replenishment report %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, report)
+}
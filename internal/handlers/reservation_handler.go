@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type ReservationHandler struct {
+	ReservationService *services.ReservationService
+}
+
+func NewReservationHandler(reservationService *services.ReservationService) *ReservationHandler {
+	return &ReservationHandler{
+		ReservationService: reservationService,
+	}
+}
+
+func (h *ReservationHandler) Reserve(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReservationHandler][Reserve]"
+	log.InfofWithContext(ctx, logTag+" reserving inventory")
+
+	var body struct {
+		TenantID   string `json:"tenant_id" validate:"required"`
+		SellerID   string `json:"seller_id" validate:"required"`
+		SKUCode    string `json:"sku_code" validate:"required,min=1"`
+		HubID      int    `json:"hub_id" validate:"required,min=1"`
+		OrderRef   string `json:"order_ref" validate:"required,min=1"`
+		Quantity   int64  `json:"quantity" validate:"required,min=1"`
+		TTLSeconds int64  `json:"ttl_seconds,omitempty" validate:"omitempty,min=1"`
+		Actor      string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	ttl := time.Duration(body.TTLSeconds) * time.Second
+	reservation, err := h.ReservationService.Reserve(ctx, body.TenantID, body.SellerID, body.SKUCode, body.HubID, body.OrderRef, body.Quantity, ttl, storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to reserve inventory %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" inventory reserved successfully")
+	utils.SuccessReponse(c, http.StatusCreated, reservation)
+}
+
+func (h *ReservationHandler) Release(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReservationHandler][Release]"
+	log.InfofWithContext(ctx, logTag+" releasing reservation")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	reservation, err := h.ReservationService.Release(ctx, body.TenantID, body.ID, storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to release reservation %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, reservation)
+}
+
+func (h *ReservationHandler) Commit(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReservationHandler][Commit]"
+	log.InfofWithContext(ctx, logTag+" committing reservation")
+
+	var body struct {
+		TenantID string   `json:"tenant_id" validate:"required"`
+		ID       int64    `json:"id" validate:"required,min=1"`
+		Actor    string   `json:"actor,omitempty"`
+		Serials  []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	reservation, err := h.ReservationService.Commit(ctx, body.TenantID, body.ID, storage.MovementInfo{Actor: body.Actor, Serials: body.Serials})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to commit reservation %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, reservation)
+}
+
+func (h *ReservationHandler) GetReservations(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReservationHandler][GetReservations]"
+	log.InfofWithContext(ctx, logTag+" getting reservations")
+
+	var body struct {
+		ID       int64  `json:"id,omitempty" validate:"required_without=OrderRef,omitempty,min=1"`
+		TenantID string `json:"tenant_id" validate:"required"`
+		OrderRef string `json:"order_ref,omitempty" validate:"required_without=ID"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	if body.ID > 0 {
+		reservation, err := h.ReservationService.GetReservation(ctx, body.TenantID, body.ID)
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get reservation %v", err)
+			c.JSON(errorStatus(err).Code(), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		utils.SuccessReponse(c, http.StatusOK, reservation)
+		return
+	}
+
+	reservations, err := h.ReservationService.GetOrderReservations(ctx, body.TenantID, body.OrderRef)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get reservations %v", err)
+		c.JSON(http.StatusInternalServerError.Code(), gin.H{
+			"error": "Failed to fetch reservations",
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"items": reservations,
+		"count": len(reservations),
+	})
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type ReturnHandler struct {
+	ReturnService *services.ReturnService
+}
+
+func NewReturnHandler(returnService *services.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		ReturnService: returnService,
+	}
+}
+
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][CreateReturn]"
+	log.InfofWithContext(ctx, logTag+" creating return")
+
+	var body struct {
+		TenantID  string            `json:"tenant_id" validate:"required"`
+		SellerID  string            `json:"seller_id" validate:"required"`
+		Reference string            `json:"reference" validate:"required,min=1,max=100"`
+		OrderRef  string            `json:"order_ref,omitempty" validate:"max=100"`
+		Reason    string            `json:"reason,omitempty" validate:"max=500"`
+		Actor     string            `json:"actor,omitempty"`
+		Lines     []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	rma, err := h.ReturnService.CreateReturn(ctx, body.TenantID, body.SellerID, body.Reference, body.OrderRef, body.Reason, body.Actor, toSKUQuantities(body.Lines))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create return %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" return created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, rma)
+}
+
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][GetReturn]"
+	log.InfofWithContext(ctx, logTag+" getting return")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	rma, err := h.ReturnService.GetReturn(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get return %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, rma)
+}
+
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][ReceiveReturn]"
+	log.InfofWithContext(ctx, logTag+" receiving return")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		HubID    int    `json:"hub_id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+		Lines    []struct {
+			SKUCode  string `json:"sku_code" validate:"required,min=1"`
+			Quantity int64  `json:"quantity" validate:"required,min=1"`
+		} `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	lines := make([]services.SKUQuantity, 0, len(body.Lines))
+	for _, line := range body.Lines {
+		lines = append(lines, services.SKUQuantity{
+			SKUCode:  line.SKUCode,
+			Quantity: line.Quantity,
+		})
+	}
+
+	rma, err := h.ReturnService.ReceiveReturn(ctx, body.TenantID, body.ID, body.HubID, lines, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive return %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, rma)
+}
+
+func (h *ReturnHandler) DisposeReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][DisposeReturn]"
+	log.InfofWithContext(ctx, logTag+" disposing returned units")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+		Lines    []struct {
+			SKUCode     string   `json:"sku_code" validate:"required,min=1"`
+			Disposition string   `json:"disposition" validate:"required,oneof=restock damaged destroy return_to_seller"`
+			Quantity    int64    `json:"quantity" validate:"required,min=1"`
+			Serials     []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+			Note        string   `json:"note,omitempty" validate:"max=500"`
+		} `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	dispositions := make([]services.ReturnDisposition, 0, len(body.Lines))
+	for _, line := range body.Lines {
+		dispositions = append(dispositions, services.ReturnDisposition{
+			SKUCode:     line.SKUCode,
+			Disposition: line.Disposition,
+			Quantity:    line.Quantity,
+			Serials:     line.Serials,
+			Note:        line.Note,
+		})
+	}
+
+	rma, err := h.ReturnService.DisposeReturn(ctx, body.TenantID, body.ID, dispositions, storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to dispose returned units %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, rma)
+}
+
+func (h *ReturnHandler) CompleteReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][CompleteReturn]"
+	log.InfofWithContext(ctx, logTag+" completing return")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	rma, err := h.ReturnService.CompleteReturn(ctx, body.TenantID, body.ID, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to complete return %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, rma)
+}
+
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ReturnHandler][CancelReturn]"
+	log.InfofWithContext(ctx, logTag+" cancelling return")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	rma, err := h.ReturnService.CancelReturn(ctx, body.TenantID, body.ID, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel return %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, rma)
+}
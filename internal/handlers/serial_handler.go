@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type SerialHandler struct {
+	SerialService *services.SerialService
+}
+
+func NewSerialHandler(serialService *services.SerialService) *SerialHandler {
+	return &SerialHandler{
+		SerialService: serialService,
+	}
+}
+
+func (h *SerialHandler) GetSerial(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SerialHandler][GetSerial]"
+	log.InfofWithContext(ctx, logTag+" getting serial")
+
+	var body struct {
+		TenantID     string `json:"tenant_id" validate:"required"`
+		SerialNumber string `json:"serial_number" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	serials, err := h.SerialService.GetSerial(ctx, body.TenantID, body.SerialNumber)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get serial %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"serials": serials,
+		"count":   len(serials),
+	})
+}
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type ShipmentHandler struct {
+	ShipmentService *services.ShipmentService
+}
+
+func NewShipmentHandler(shipmentService *services.ShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{
+		ShipmentService: shipmentService,
+	}
+}
+
+func (h *ShipmentHandler) CreateShipment(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][CreateShipment]"
+	log.InfofWithContext(ctx, logTag+" creating shipment")
+
+	var body struct {
+		TenantID     string `json:"tenant_id" validate:"required"`
+		AllocationID int64  `json:"allocation_id" validate:"required,min=1"`
+		HubID        int    `json:"hub_id" validate:"required,min=1"`
+		Carrier      string `json:"carrier" validate:"required,min=1,max=100"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	shipment, err := h.ShipmentService.CreateShipment(ctx, body.TenantID, body.AllocationID, body.HubID, body.Carrier)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create shipment %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" shipment created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, shipment)
+}
+
+func (h *ShipmentHandler) GetShipment(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][GetShipment]"
+	log.InfofWithContext(ctx, logTag+" getting shipment")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	shipment, err := h.ShipmentService.GetShipment(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get shipment %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) CancelShipment(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][CancelShipment]"
+	log.InfofWithContext(ctx, logTag+" cancelling shipment")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	shipment, err := h.ShipmentService.CancelShipment(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel shipment %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) CloseShipment(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][CloseShipment]"
+	log.InfofWithContext(ctx, logTag+" closing shipment")
+
+	var body struct {
+		TenantID       string `json:"tenant_id" validate:"required"`
+		ID             int64  `json:"id" validate:"required,min=1"`
+		TrackingNumber string `json:"tracking_number" validate:"required,min=1,max=100"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	shipment, err := h.ShipmentService.CloseShipment(ctx, body.TenantID, body.ID, body.TrackingNumber)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to close shipment %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, shipment)
+}
+
+func (h *ShipmentHandler) AddCarton(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][AddCarton]"
+	log.InfofWithContext(ctx, logTag+" adding carton")
+
+	var body struct {
+		TenantID   string `json:"tenant_id" validate:"required"`
+		ShipmentID int64  `json:"shipment_id" validate:"required,min=1"`
+		Code       string `json:"code,omitempty" validate:"omitempty,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	carton, err := h.ShipmentService.AddCarton(ctx, body.TenantID, body.ShipmentID, body.Code)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to add carton %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusCreated, carton)
+}
+
+func (h *ShipmentHandler) PackItem(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][PackItem]"
+	log.InfofWithContext(ctx, logTag+" packing item")
+
+	var body struct {
+		TenantID   string   `json:"tenant_id" validate:"required"`
+		ShipmentID int64    `json:"shipment_id" validate:"required,min=1"`
+		CartonID   int64    `json:"carton_id" validate:"required,min=1"`
+		SKUCode    string   `json:"sku_code" validate:"required,min=1"`
+		Quantity   int64    `json:"quantity" validate:"required,min=1"`
+		Serials    []string `json:"serials,omitempty" validate:"omitempty,max=1000,dive,required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	carton, err := h.ShipmentService.PackItem(ctx, body.TenantID, body.ShipmentID, body.CartonID, body.SKUCode, body.Quantity, body.Serials)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to pack item %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, carton)
+}
+
+func (h *ShipmentHandler) SealCarton(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][SealCarton]"
+	log.InfofWithContext(ctx, logTag+" sealing carton")
+
+	var body struct {
+		TenantID string  `json:"tenant_id" validate:"required"`
+		CartonID int64   `json:"carton_id" validate:"required,min=1"`
+		LengthCm float64 `json:"length_cm" validate:"required,gt=0"`
+		WidthCm  float64 `json:"width_cm" validate:"required,gt=0"`
+		HeightCm float64 `json:"height_cm" validate:"required,gt=0"`
+		WeightKg float64 `json:"weight_kg" validate:"required,gt=0"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	carton, err := h.ShipmentService.SealCarton(ctx, body.TenantID, body.CartonID, storage.CartonMeasure{
+		LengthCm: body.LengthCm,
+		WidthCm:  body.WidthCm,
+		HeightCm: body.HeightCm,
+		WeightKg: body.WeightKg,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to seal carton %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, carton)
+}
+
+// GetManifest returns the carrier manifest of a hub for one day, as JSON or
+// as a CSV download with one row per sku per carton. Like the other downloads
+// it is a GET that takes its filter from the query string
+func (h *ShipmentHandler) GetManifest(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[ShipmentHandler][GetManifest]"
+	log.InfofWithContext(ctx, logTag+" getting manifest")
+
+	var query struct {
+		TenantID string `form:"tenant_id" validate:"required"`
+		HubID    int    `form:"hub_id" validate:"required,min=1"`
+		Carrier  string `form:"carrier" validate:"required,min=1,max=100"`
+		Date     string `form:"date" validate:"required,datetime=2006-01-02"`
+		Format   string `form:"format" validate:"omitempty,oneof=json csv"`
+	}
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind query %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, query); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	day, err := time.Parse(time.DateOnly, query.Date)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to parse date %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	manifest, err := h.ShipmentService.GetManifest(ctx, query.TenantID, query.HubID, query.Carrier, day)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get manifest %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if query.Format != "csv" {
+		utils.SuccessReponse(c, http.StatusOK, manifest)
+		return
+	}
+
+	data, err := manifestCSV(manifest)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to write manifest csv %v", err)
+		c.JSON(http.StatusInternalServerError.Code(), gin.H{
+			"error": "Failed to write manifest",
+		})
+		return
+	}
+
+	// the carrier is free text, FormatMediaType quotes or encodes it so it
+	// cannot break out of the header
+	filename := fmt.Sprintf("manifest_%d_%s_%s.csv", manifest.HubID, manifest.Carrier, manifest.Date)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Data(http.StatusOK.Code(), "text/csv", data)
+}
+
+func manifestCSV(manifest *models.Manifest) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	_ = w.Write([]string{"hub", "carrier", "date", "shipment_id", "order_ref", "tracking_number", "carton", "length_cm", "width_cm", "height_cm", "weight_kg", "sku_code", "sku_name", "quantity"})
+	for _, line := range manifest.Lines {
+		_ = w.Write([]string{
+			manifest.HubName,
+			manifest.Carrier,
+			manifest.Date,
+			strconv.FormatInt(line.ShipmentID, 10),
+			line.OrderRef,
+			line.TrackingNumber,
+			line.CartonCode,
+			formatMeasure(line.LengthCm),
+			formatMeasure(line.WidthCm),
+			formatMeasure(line.HeightCm),
+			formatMeasure(line.WeightKg),
+			line.SKUCode,
+			line.SKUName,
+			strconv.FormatInt(line.Quantity, 10),
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func formatMeasure(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -32,6 +32,7 @@ func (h *SKUHandler) CreateSKU(c *gin.Context) {
 		SKUCode  string         `json:"sku_code" validate:"required,min=1,max=50"`
 		Name     string         `json:"name" validate:"required,min=2,max=200"`
 		Metadata datatypes.JSON `json:"metadata,omitempty"`
+		Serialized bool         `json:"serialized,omitempty"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
@@ -50,7 +51,7 @@ func (h *SKUHandler) CreateSKU(c *gin.Context) {
 		return
 	}
 
-	sku, err := h.SKUService.CreateSKU(ctx, body.TenantID, body.SellerID, body.SKUCode, body.Name, body.Metadata)
+	sku, err := h.SKUService.CreateSKU(ctx, body.TenantID, body.SellerID, body.SKUCode, body.Name, body.Metadata, body.Serialized)
 	if err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to create SKU: %v", err)
 		c.JSON(http.StatusInternalServerError.Code(), gin.H{
@@ -66,6 +67,7 @@ func (h *SKUHandler) CreateSKU(c *gin.Context) {
 		"SKUCode":  sku.SKUCode,
 		"Name":     sku.Name,
 		"Metadata": sku.MetaData,
+		"Serialized": sku.Serialized,
 	}
 
 	log.InfofWithContext(ctx, logTag+" SKU created successfully")
@@ -132,3 +134,136 @@ func (h *SKUHandler) GetSKUsByCodes(c *gin.Context) {
 
 	utils.SuccessReponse(c, http.StatusOK, responseData)
 }
+
+func (h *SKUHandler) UpdateSKU(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SKUHandler][UpdateSKU]"
+	log.InfofWithContext(ctx, logTag+" updating SKU")
+
+	var body struct {
+		TenantID string         `json:"tenant_id" validate:"required"`
+		SellerID string         `json:"seller_id" validate:"required"`
+		SKUCode  string         `json:"sku_code" validate:"required,min=1,max=50"`
+		Name     *string        `json:"name,omitempty" validate:"omitempty,min=2,max=200"`
+		Metadata datatypes.JSON `json:"metadata,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	sku, err := h.SKUService.UpdateSKU(ctx, body.TenantID, body.SellerID, body.SKUCode, body.Name, body.Metadata)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to update SKU %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, sku)
+}
+
+func (h *SKUHandler) ArchiveSKU(c *gin.Context) {
+	h.setSKUArchived(c, "[SKUHandler][ArchiveSKU]", true)
+}
+
+func (h *SKUHandler) RestoreSKU(c *gin.Context) {
+	h.setSKUArchived(c, "[SKUHandler][RestoreSKU]", false)
+}
+
+func (h *SKUHandler) setSKUArchived(c *gin.Context, logTag string, archived bool) {
+	ctx := c.Request.Context()
+	log.InfofWithContext(ctx, logTag+" setting SKU archived to %t", archived)
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		SellerID string `json:"seller_id" validate:"required"`
+		SKUCode  string `json:"sku_code" validate:"required,min=1,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	sku, err := h.SKUService.SetSKUArchived(ctx, body.TenantID, body.SellerID, body.SKUCode, archived)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to set SKU archived %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, sku)
+}
+
+// DeleteSKU removes a sku, refused while it has stock, held reservations or
+// order lines pointing at it
+func (h *SKUHandler) DeleteSKU(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SKUHandler][DeleteSKU]"
+	log.InfofWithContext(ctx, logTag+" deleting SKU")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		SellerID string `json:"seller_id" validate:"required"`
+		SKUCode  string `json:"sku_code" validate:"required,min=1,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	if err := h.SKUService.DeleteSKU(ctx, body.TenantID, body.SellerID, body.SKUCode); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to delete SKU %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"sku_code": body.SKUCode,
+		"deleted":  true,
+	})
+}
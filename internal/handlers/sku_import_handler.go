@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+// maxImportFileBytes caps the size of an uploaded import file
+const maxImportFileBytes = 20 << 20
+
+type SKUImportHandler struct {
+	SKUImportService *services.SKUImportService
+}
+
+func NewSKUImportHandler(skuImportService *services.SKUImportService) *SKUImportHandler {
+	return &SKUImportHandler{
+		SKUImportService: skuImportService,
+	}
+}
+
+// CreateSKUImport takes a multipart upload with the csv in the file field
+func (h *SKUImportHandler) CreateSKUImport(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SKUImportHandler][CreateSKUImport]"
+	log.InfofWithContext(ctx, logTag+" queueing sku import")
+
+	var body struct {
+		TenantID string `form:"tenant_id" validate:"required"`
+		SellerID string `form:"seller_id" validate:"required"`
+		Actor    string `form:"actor"`
+	}
+
+	if err := c.ShouldBind(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind form %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get uploaded file %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": "a csv file is required in the file field",
+		})
+		return
+	}
+	if header.Size > maxImportFileBytes {
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": fmt.Sprintf("file is larger than %d bytes", maxImportFileBytes),
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to open uploaded file %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to read uploaded file %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	job, err := h.SKUImportService.CreateSKUImport(ctx, body.TenantID, body.SellerID, header.Filename, content, body.Actor)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to queue sku import %v", err)
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" sku import queued successfully")
+	utils.SuccessReponse(c, http.StatusAccepted, job)
+}
+
+func (h *SKUImportHandler) GetSKUImport(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SKUImportHandler][GetSKUImport]"
+	log.InfofWithContext(ctx, logTag+" getting sku import")
+
+	var body struct {
+		ID int64 `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	job, err := h.SKUImportService.GetImport(ctx, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get sku import %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, gin.H{
+		"job":              job,
+		"error_report_url": fmt.Sprintf("/api/v1/skus/imports/%d/errors", job.ID),
+	})
+}
+
+// DownloadSKUImportErrors returns the rejected rows of an import as a csv
+func (h *SKUImportHandler) DownloadSKUImportErrors(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[SKUImportHandler][DownloadSKUImportErrors]"
+	log.InfofWithContext(ctx, logTag+" downloading sku import errors")
+
+	var uri struct {
+		ID int64 `uri:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind uri %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, uri); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	job, rowErrors, err := h.SKUImportService.GetImportErrors(ctx, uri.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get sku import errors %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	data, err := importErrorsCSV(rowErrors)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to write import errors csv %v", err)
+		c.JSON(http.StatusInternalServerError.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=import_%d_errors.csv", job.ID))
+	c.Data(http.StatusOK.Code(), "text/csv", data)
+}
+
+func importErrorsCSV(rowErrors []models.ImportJobError) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	_ = w.Write([]string{"row_number", "sku_code", "error"})
+	for _, rowError := range rowErrors {
+		_ = w.Write([]string{
+			strconv.Itoa(rowError.RowNumber),
+			rowError.SKUCode,
+			rowError.Message,
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/omniful/go_commons/http"
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/services"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type TransferHandler struct {
+	TransferService *services.TransferService
+}
+
+func NewTransferHandler(transferService *services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		TransferService: transferService,
+	}
+}
+
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[TransferHandler][CreateTransfer]"
+	log.InfofWithContext(ctx, logTag+" creating transfer order")
+
+	var body struct {
+		TenantID         string            `json:"tenant_id" validate:"required"`
+		SellerID         string            `json:"seller_id" validate:"required"`
+		SourceHubID      int               `json:"source_hub_id" validate:"required,min=1"`
+		DestinationHubID int               `json:"destination_hub_id" validate:"required,min=1,nefield=SourceHubID"`
+		Reference        string            `json:"reference,omitempty"`
+		Lines            []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	transfer, err := h.TransferService.CreateTransfer(ctx, body.TenantID, body.SellerID, body.SourceHubID, body.DestinationHubID, body.Reference, toSKUQuantities(body.Lines))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create transfer order %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer order created successfully")
+	utils.SuccessReponse(c, http.StatusCreated, transfer)
+}
+
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[TransferHandler][GetTransfer]"
+	log.InfofWithContext(ctx, logTag+" getting transfer order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	transfer, err := h.TransferService.GetTransfer(ctx, body.TenantID, body.ID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get transfer order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) DispatchTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[TransferHandler][DispatchTransfer]"
+	log.InfofWithContext(ctx, logTag+" dispatching transfer order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	transfer, err := h.TransferService.DispatchTransfer(ctx, body.TenantID, body.ID, storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to dispatch transfer order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) ReceiveTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[TransferHandler][ReceiveTransfer]"
+	log.InfofWithContext(ctx, logTag+" receiving transfer order")
+
+	var body struct {
+		TenantID string            `json:"tenant_id" validate:"required"`
+		ID       int64             `json:"id" validate:"required,min=1"`
+		Actor    string            `json:"actor,omitempty"`
+		Lines    []skuQuantityLine `json:"lines" validate:"required,min=1,max=500,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	transfer, err := h.TransferService.ReceiveTransfer(ctx, body.TenantID, body.ID, toSKUQuantities(body.Lines), storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive transfer order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	ctx := c.Request.Context()
+	logTag := "[TransferHandler][CancelTransfer]"
+	log.InfofWithContext(ctx, logTag+" cancelling transfer order")
+
+	var body struct {
+		TenantID string `json:"tenant_id" validate:"required"`
+		ID       int64  `json:"id" validate:"required,min=1"`
+		Actor    string `json:"actor,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to bind JSON %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := validator.ValidateStruct(ctx, body); err.Exists() {
+		log.ErrorfWithContext(ctx, logTag+" please enter valid input %v", err)
+		c.JSON(http.StatusBadRequest.Code(), gin.H{
+			"error":  err.ErrorMessage(),
+			"errors": err.ErrorMap(),
+		})
+		return
+	}
+
+	transfer, err := h.TransferService.CancelTransfer(ctx, body.TenantID, body.ID, storage.MovementInfo{Actor: body.Actor})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel transfer order %v", err)
+		c.JSON(errorStatus(err).Code(), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	utils.SuccessReponse(c, http.StatusOK, transfer)
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
+)
+
+type AllocationService struct {
+	AllocationRepo *storage.AllocationRepo
+	InventoryRepo  *storage.InventoryRepo
+	SKURepo        *storage.SKURepo
+	HubRepo        *storage.HubRepo
+	SplitPolicy    string
+	HoldTTL        time.Duration
+}
+
+func NewAllocationService(allocationRepo *storage.AllocationRepo, inventoryRepo *storage.InventoryRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo, splitPolicy string, holdTTL time.Duration) *AllocationService {
+	return &AllocationService{
+		AllocationRepo: allocationRepo,
+		InventoryRepo:  inventoryRepo,
+		SKURepo:        skuRepo,
+		HubRepo:        hubRepo,
+		SplitPolicy:    splitPolicy,
+		HoldTTL:        holdTTL,
+	}
+}
+
+// allocationHub is a hub that stocks at least one sku of the order, hubs
+// without coordinates sort after every hub with a known distance
+type allocationHub struct {
+	id        int
+	distance  *float64
+	available map[int]int64
+}
+
+// allocationDemand is one merged sku line of the order
+type allocationDemand struct {
+	skuID    int
+	skuCode  string
+	quantity int64
+}
+
+// AllocateOrder picks the hubs that ship an order to the delivery point,
+// nearest hubs first and as far split as the policy allows. The chosen units
+// are reserved against orderRef, whatever no hub can cover stays on a line
+// without a hub
+func (s *AllocationService) AllocateOrder(ctx context.Context, tenantID, sellerID, orderRef, splitPolicy string, latitude, longitude float64, lines []SKUQuantity, info storage.MovementInfo) (*models.OrderAllocation, error) {
+	logTag := "[AllocationService][AllocateOrder]"
+	log.InfofWithContext(ctx, logTag+" allocating order %s with %d lines", orderRef, len(lines))
+
+	if splitPolicy == "" {
+		splitPolicy = s.SplitPolicy
+	}
+	if splitPolicy == "" {
+		splitPolicy = models.SplitPolicyLine
+	}
+	switch splitPolicy {
+	case models.SplitPolicyNone, models.SplitPolicyLine, models.SplitPolicyUnit:
+	default:
+		return nil, fmt.Errorf("unknown split policy %s", splitPolicy)
+	}
+
+	skuIDs, err := resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, lineCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+
+	var demand []allocationDemand
+	merged := make(map[string]int)
+	for _, line := range lines {
+		if idx, ok := merged[line.SKUCode]; ok {
+			demand[idx].quantity += line.Quantity
+			continue
+		}
+		merged[line.SKUCode] = len(demand)
+		demand = append(demand, allocationDemand{
+			skuID:    skuIDs[line.SKUCode],
+			skuCode:  line.SKUCode,
+			quantity: line.Quantity,
+		})
+	}
+
+	hubs, err := s.candidateHubs(ctx, tenantID, sellerID, demand, latitude, longitude)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get candidate hubs %v", err)
+		return nil, err
+	}
+
+	allocation := &models.OrderAllocation{
+		TenantID:          tenantID,
+		SellerID:          sellerID,
+		OrderRef:          orderRef,
+		SplitPolicy:       splitPolicy,
+		DeliveryLatitude:  latitude,
+		DeliveryLongitude: longitude,
+	}
+
+	allocation.Lines = allocateLines(splitPolicy, hubs, demand)
+	allocation.Status = allocationStatus(allocation.Lines)
+
+	if info.Reference == "" {
+		info.Reference = "allocation:" + orderRef
+	}
+
+	if err := s.AllocationRepo.Create(ctx, allocation, time.Now().Add(s.HoldTTL), info); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create order allocation %v", err)
+		return nil, fmt.Errorf("failed to create order allocation %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" order allocated with ID: %d, status %s", allocation.ID, allocation.Status)
+	return allocation, nil
+}
+
+func (s *AllocationService) GetAllocation(ctx context.Context, tenantID string, id int64) (*models.OrderAllocation, error) {
+	logTag := "[AllocationService][GetAllocation]"
+	log.InfofWithContext(ctx, logTag+" fetching order allocation %d", id)
+
+	allocation, err := s.AllocationRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch order allocation %v", err)
+		return nil, fmt.Errorf("failed to fetch order allocation %w", err)
+	}
+
+	return allocation, nil
+}
+
+// candidateHubs loads the availability of the ordered skus at every active
+// hub of the tenant and orders the hubs by distance to the delivery point
+func (s *AllocationService) candidateHubs(ctx context.Context, tenantID, sellerID string, demand []allocationDemand, latitude, longitude float64) ([]allocationHub, error) {
+	skuIDs := make([]int, 0, len(demand))
+	for _, line := range demand {
+		skuIDs = append(skuIDs, line.skuID)
+	}
+
+	availability, err := s.InventoryRepo.GetHubAvailability(ctx, tenantID, sellerID, skuIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability %w", err)
+	}
+
+	activeHubs, err := s.HubRepo.GetActiveByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hubs %w", err)
+	}
+	hubByID := make(map[int]models.Hub, len(activeHubs))
+	for _, hub := range activeHubs {
+		hubByID[hub.ID] = hub
+	}
+
+	byHub := make(map[int]*allocationHub)
+	var hubs []*allocationHub
+	for _, row := range availability {
+		if _, ok := hubByID[row.HubID]; !ok || row.Available <= 0 {
+			continue
+		}
+		hub, ok := byHub[row.HubID]
+		if !ok {
+			hub = &allocationHub{id: row.HubID, available: make(map[int]int64)}
+			if lat, lng, ok := hubByID[row.HubID].Coordinates(); ok {
+				distance := utils.HaversineKm(latitude, longitude, lat, lng)
+				hub.distance = &distance
+			}
+			byHub[row.HubID] = hub
+			hubs = append(hubs, hub)
+		}
+		hub.available[row.SKUID] += row.Available
+	}
+
+	sort.Slice(hubs, func(i, j int) bool {
+		a, b := hubs[i], hubs[j]
+		switch {
+		case a.distance != nil && b.distance == nil:
+			return true
+		case a.distance == nil && b.distance != nil:
+			return false
+		case a.distance != nil && *a.distance != *b.distance:
+			return *a.distance < *b.distance
+		}
+		return a.id < b.id
+	})
+
+	ordered := make([]allocationHub, 0, len(hubs))
+	for _, hub := range hubs {
+		ordered = append(ordered, *hub)
+	}
+	return ordered, nil
+}
+
+// allocateLines plans the order under the split policy, hubs come nearest
+// first and their availability is used up as lines are planned
+func allocateLines(splitPolicy string, hubs []allocationHub, demand []allocationDemand) []models.OrderAllocationLine {
+	switch splitPolicy {
+	case models.SplitPolicyNone:
+		return allocateSingleHub(hubs, demand)
+	case models.SplitPolicyUnit:
+		return allocateByUnit(hubs, demand)
+	default:
+		return allocateByLine(hubs, demand)
+	}
+}
+
+// allocateSingleHub ships the order from the hub covering the most units,
+// the nearest one when several cover the same
+func allocateSingleHub(hubs []allocationHub, demand []allocationDemand) []models.OrderAllocationLine {
+	var best *allocationHub
+	var bestCovered int64
+	for i := range hubs {
+		var covered int64
+		for _, line := range demand {
+			covered += min(hubs[i].available[line.skuID], line.quantity)
+		}
+		if covered > bestCovered {
+			best, bestCovered = &hubs[i], covered
+		}
+	}
+
+	var lines []models.OrderAllocationLine
+	for _, line := range demand {
+		remaining := line.quantity
+		if best != nil {
+			if take := min(best.available[line.skuID], remaining); take > 0 {
+				lines = append(lines, allocationLine(line, best, take))
+				remaining -= take
+			}
+		}
+		if remaining > 0 {
+			lines = append(lines, allocationLine(line, nil, remaining))
+		}
+	}
+	return lines
+}
+
+// allocateByLine ships every line whole from one hub, hubs already chosen for
+// an earlier line are preferred so the order travels in as few shipments as
+// possible. A line no hub covers fully takes what the best hub has
+func allocateByLine(hubs []allocationHub, demand []allocationDemand) []models.OrderAllocationLine {
+	used := make(map[int]bool)
+
+	var lines []models.OrderAllocationLine
+	for _, line := range demand {
+		var chosen *allocationHub
+		for i := range hubs {
+			if hubs[i].available[line.skuID] < line.quantity {
+				continue
+			}
+			if chosen == nil || (used[hubs[i].id] && !used[chosen.id]) {
+				chosen = &hubs[i]
+			}
+		}
+
+		if chosen == nil {
+			for i := range hubs {
+				if chosen == nil || hubs[i].available[line.skuID] > chosen.available[line.skuID] {
+					chosen = &hubs[i]
+				}
+			}
+		}
+
+		remaining := line.quantity
+		if chosen != nil {
+			if take := min(chosen.available[line.skuID], remaining); take > 0 {
+				lines = append(lines, allocationLine(line, chosen, take))
+				chosen.available[line.skuID] -= take
+				used[chosen.id] = true
+				remaining -= take
+			}
+		}
+		if remaining > 0 {
+			lines = append(lines, allocationLine(line, nil, remaining))
+		}
+	}
+	return lines
+}
+
+// allocateByUnit fills every line from the nearest hubs outward
+func allocateByUnit(hubs []allocationHub, demand []allocationDemand) []models.OrderAllocationLine {
+	var lines []models.OrderAllocationLine
+	for _, line := range demand {
+		remaining := line.quantity
+		for i := 0; remaining > 0 && i < len(hubs); i++ {
+			take := min(hubs[i].available[line.skuID], remaining)
+			if take <= 0 {
+				continue
+			}
+			lines = append(lines, allocationLine(line, &hubs[i], take))
+			hubs[i].available[line.skuID] -= take
+			remaining -= take
+		}
+		if remaining > 0 {
+			lines = append(lines, allocationLine(line, nil, remaining))
+		}
+	}
+	return lines
+}
+
+func allocationLine(line allocationDemand, hub *allocationHub, quantity int64) models.OrderAllocationLine {
+	allocated := models.OrderAllocationLine{
+		SKUID:    line.skuID,
+		SKUCode:  line.skuCode,
+		Quantity: quantity,
+	}
+	if hub != nil {
+		hubID := hub.id
+		allocated.HubID = &hubID
+		allocated.DistanceKm = hub.distance
+	}
+	return allocated
+}
+
+func allocationStatus(lines []models.OrderAllocationLine) string {
+	var allocated, unallocated bool
+	for _, line := range lines {
+		if line.HubID != nil {
+			allocated = true
+		} else {
+			unallocated = true
+		}
+	}
+
+	switch {
+	case !unallocated:
+		return models.AllocationStatusAllocated
+	case allocated:
+		return models.AllocationStatusPartial
+	default:
+		return models.AllocationStatusUnallocated
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+func TestAllocateLines(t *testing.T) {
+	// hub 1 is the nearest, hub 2 further out, hub 3 has no coordinates
+	hubs := func() []allocationHub {
+		near, far := 1.0, 10.0
+		return []allocationHub{
+			{id: 1, distance: &near, available: map[int]int64{1: 5}},
+			{id: 2, distance: &far, available: map[int]int64{1: 10, 2: 4}},
+			{id: 3, available: map[int]int64{2: 1}},
+		}
+	}
+	demand := func(quantities ...int64) []allocationDemand {
+		lines := make([]allocationDemand, 0, len(quantities))
+		for i, quantity := range quantities {
+			if quantity > 0 {
+				lines = append(lines, allocationDemand{skuID: i + 1, skuCode: fmt.Sprintf("SKU-%d", i+1), quantity: quantity})
+			}
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		demand     []allocationDemand
+		wantLines  []string
+		wantStatus string
+	}{
+		{
+			name:       "none ships everything from the hub covering the most",
+			policy:     models.SplitPolicyNone,
+			demand:     demand(4, 3),
+			wantLines:  []string{"SKU-1:4@2", "SKU-2:3@2"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:       "none leaves what the best hub lacks unallocated",
+			policy:     models.SplitPolicyNone,
+			demand:     demand(12, 3),
+			wantLines:  []string{"SKU-1:10@2", "SKU-1:2@-", "SKU-2:3@2"},
+			wantStatus: models.AllocationStatusPartial,
+		},
+		{
+			name:       "line picks the nearest hub covering each line whole",
+			policy:     models.SplitPolicyLine,
+			demand:     demand(4, 3),
+			wantLines:  []string{"SKU-1:4@1", "SKU-2:3@2"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:   "line prefers a hub already used for the order",
+			policy: models.SplitPolicyLine,
+			demand: []allocationDemand{
+				{skuID: 2, skuCode: "SKU-2", quantity: 3},
+				{skuID: 1, skuCode: "SKU-1", quantity: 4},
+			},
+			wantLines:  []string{"SKU-2:3@2", "SKU-1:4@2"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:       "line does not split a line a farther hub covers whole",
+			policy:     models.SplitPolicyLine,
+			demand:     demand(8),
+			wantLines:  []string{"SKU-1:8@2"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:       "unit splits a line across hubs nearest first",
+			policy:     models.SplitPolicyUnit,
+			demand:     demand(8),
+			wantLines:  []string{"SKU-1:5@1", "SKU-1:3@2"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:       "unit reaches hubs without coordinates last",
+			policy:     models.SplitPolicyUnit,
+			demand:     demand(0, 5),
+			wantLines:  []string{"SKU-2:4@2", "SKU-2:1@3"},
+			wantStatus: models.AllocationStatusAllocated,
+		},
+		{
+			name:       "a sku no hub stocks stays unallocated",
+			policy:     models.SplitPolicyUnit,
+			demand:     demand(0, 0, 2),
+			wantLines:  []string{"SKU-3:2@-"},
+			wantStatus: models.AllocationStatusUnallocated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := allocateLines(tt.policy, hubs(), tt.demand)
+
+			got := make([]string, 0, len(lines))
+			for _, line := range lines {
+				hub := "-"
+				if line.HubID != nil {
+					hub = fmt.Sprint(*line.HubID)
+				}
+				got = append(got, fmt.Sprintf("%s:%d@%s", line.SKUCode, line.Quantity, hub))
+			}
+			if !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("lines = %v, want %v", got, tt.wantLines)
+			}
+			if status := allocationStatus(lines); status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
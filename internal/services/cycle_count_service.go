@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type CycleCountService struct {
+	CycleCountRepo      *storage.CycleCountRepo
+	SKURepo             *storage.SKURepo
+	HubRepo             *storage.HubRepo
+	RecountThresholdPct int
+}
+
+func NewCycleCountService(cycleCountRepo *storage.CycleCountRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo, recountThresholdPct int) *CycleCountService {
+	return &CycleCountService{
+		CycleCountRepo:      cycleCountRepo,
+		SKURepo:             skuRepo,
+		HubRepo:             hubRepo,
+		RecountThresholdPct: recountThresholdPct,
+	}
+}
+
+// CreateCycleCount generates a count task for a hub, skuCodes and locationIDs
+// are optional filters, with locations the count is taken per bin
+func (s *CycleCountService) CreateCycleCount(ctx context.Context, tenantID, sellerID string, hubID int, skuCodes []string, locationIDs []int, createdBy string) (*models.CycleCount, error) {
+	logTag := "[CycleCountService][CreateCycleCount]"
+	log.InfofWithContext(ctx, logTag+" creating cycle count for hub %d, seller %s", hubID, sellerID)
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != tenantID {
+		return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+	}
+
+	skuIDs := map[string]int{}
+	if len(skuCodes) > 0 {
+		skuIDs, err = resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, skuCodes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	count := &models.CycleCount{
+		TenantID:  tenantID,
+		SellerID:  sellerID,
+		HubID:     hubID,
+		CreatedBy: createdBy,
+	}
+
+	if err := s.CycleCountRepo.Create(ctx, count, skuIDs, locationIDs); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create cycle count %v", err)
+		return nil, fmt.Errorf("failed to create cycle count %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" cycle count created successfully with ID: %d", count.ID)
+	return count, nil
+}
+
+func (s *CycleCountService) GetCycleCount(ctx context.Context, tenantID string, id int64) (*models.CycleCount, error) {
+	logTag := "[CycleCountService][GetCycleCount]"
+	log.InfofWithContext(ctx, logTag+" fetching cycle count %d", id)
+
+	count, err := s.CycleCountRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch cycle count %v", err)
+		return nil, fmt.Errorf("failed to fetch cycle count %w", err)
+	}
+
+	return count, nil
+}
+
+// SubmitCounts records counted quantities keyed by line id
+func (s *CycleCountService) SubmitCounts(ctx context.Context, tenantID string, id int64, counted map[int64]int64, countedBy string) (*models.CycleCount, error) {
+	logTag := "[CycleCountService][SubmitCounts]"
+	log.InfofWithContext(ctx, logTag+" submitting %d counted lines for cycle count %d", len(counted), id)
+
+	count, err := s.CycleCountRepo.SubmitCounts(ctx, tenantID, id, counted, countedBy, s.RecountThresholdPct)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to submit counts %v", err)
+		return nil, fmt.Errorf("failed to submit counts %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *CycleCountService) ApproveCycleCount(ctx context.Context, tenantID string, id int64, approvedBy string, info storage.MovementInfo) (*models.CycleCount, error) {
+	logTag := "[CycleCountService][ApproveCycleCount]"
+	log.InfofWithContext(ctx, logTag+" approving cycle count %d", id)
+
+	info.Actor = approvedBy
+	count, err := s.CycleCountRepo.Approve(ctx, tenantID, id, approvedBy, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to approve cycle count %v", err)
+		return nil, fmt.Errorf("failed to approve cycle count %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *CycleCountService) CancelCycleCount(ctx context.Context, tenantID string, id int64) (*models.CycleCount, error) {
+	logTag := "[CycleCountService][CancelCycleCount]"
+	log.InfofWithContext(ctx, logTag+" cancelling cycle count %d", id)
+
+	count, err := s.CycleCountRepo.Cancel(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel cycle count %v", err)
+		return nil, fmt.Errorf("failed to cancel cycle count %w", err)
+	}
+
+	return count, nil
+}
@@ -3,20 +3,26 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/omniful/go_commons/log"
 	"github.com/singhJasvinder101/go_wms/internal/storage"
 	"github.com/singhJasvinder101/go_wms/models"
+	"github.com/singhJasvinder101/go_wms/utils"
 	"gorm.io/datatypes"
 )
 
 type HubService struct {
-    HubRepo *storage.HubRepo
+    HubRepo       *storage.HubRepo
+    InventoryRepo *storage.InventoryRepo
+    SKURepo       *storage.SKURepo
 }
 
-func NewHubService(hubRepo *storage.HubRepo) *HubService {
+func NewHubService(hubRepo *storage.HubRepo, inventoryRepo *storage.InventoryRepo, skuRepo *storage.SKURepo) *HubService {
     return &HubService{
-        HubRepo: hubRepo,
+        HubRepo:       hubRepo,
+        InventoryRepo: inventoryRepo,
+        SKURepo:       skuRepo,
     }
 }
 
@@ -24,7 +30,10 @@ func (s *HubService) CreateHub(ctx context.Context, tenantId string, name string
     logTag := "[HubService][CreateHub]"
     log.InfofWithContext(ctx, logTag+" creating hub for tenant %s", tenantId)
 
- 
+    if err := validateHubLocation(location); err != nil {
+        return nil, err
+    }
+
     hub := &models.Hub{
         TenantID: tenantId,
         Name:     name,
@@ -66,4 +75,204 @@ func (s *HubService) GetAllHubs(ctx context.Context) ([]models.Hub, error) {
 
     log.InfofWithContext(ctx, logTag+" found %d hubs", len(hubs))
     return hubs, nil
-}
\ No newline at end of file
+}
+
+// GetNearestHubs orders the active hubs of the tenant by distance to the
+// point, hubs without coordinates are left out. With lines only the hubs that
+// can ship every line in full on their own are kept
+func (s *HubService) GetNearestHubs(ctx context.Context, tenantID, sellerID string, latitude, longitude, radiusKm float64, lines []SKUQuantity, limit int) ([]models.NearbyHub, error) {
+    logTag := "[HubService][GetNearestHubs]"
+    log.InfofWithContext(ctx, logTag+" finding hubs of tenant %s near %f,%f", tenantID, latitude, longitude)
+
+    hubs, err := s.HubRepo.GetActiveByTenant(ctx, tenantID)
+    if err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to fetch hubs: %v", err)
+        return nil, fmt.Errorf("failed to fetch hubs %w", err)
+    }
+
+    var fulfilling map[int]bool
+    if len(lines) > 0 {
+        fulfilling, err = s.fulfillingHubs(ctx, tenantID, sellerID, lines)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    nearby := []models.NearbyHub{}
+    for _, hub := range hubs {
+        lat, lng, ok := hub.Coordinates()
+        if !ok {
+            continue
+        }
+        if fulfilling != nil && !fulfilling[hub.ID] {
+            continue
+        }
+
+        distance := utils.HaversineKm(latitude, longitude, lat, lng)
+        if radiusKm > 0 && distance > radiusKm {
+            continue
+        }
+        nearby = append(nearby, models.NearbyHub{
+            HubID:      hub.ID,
+            Name:       hub.Name,
+            Location:   hub.Location,
+            DistanceKm: distance,
+        })
+    }
+
+    sort.Slice(nearby, func(i, j int) bool {
+        if nearby[i].DistanceKm != nearby[j].DistanceKm {
+            return nearby[i].DistanceKm < nearby[j].DistanceKm
+        }
+        return nearby[i].HubID < nearby[j].HubID
+    })
+
+    if limit > 0 && len(nearby) > limit {
+        nearby = nearby[:limit]
+    }
+
+    return nearby, nil
+}
+
+// fulfillingHubs returns the hubs holding enough available stock for every
+// line, repeated skus are summed
+func (s *HubService) fulfillingHubs(ctx context.Context, tenantID, sellerID string, lines []SKUQuantity) (map[int]bool, error) {
+    skuIDs, err := resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, lineCodes(lines))
+    if err != nil {
+        return nil, err
+    }
+
+    demand := make(map[int]int64, len(skuIDs))
+    for _, line := range lines {
+        demand[skuIDs[line.SKUCode]] += line.Quantity
+    }
+
+    ids := make([]int, 0, len(demand))
+    for id := range demand {
+        ids = append(ids, id)
+    }
+
+    availability, err := s.InventoryRepo.GetHubAvailability(ctx, tenantID, sellerID, ids)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get availability %w", err)
+    }
+
+    covered := make(map[int]int)
+    for _, row := range availability {
+        if row.Available >= demand[row.SKUID] {
+            covered[row.HubID]++
+        }
+    }
+
+    fulfilling := make(map[int]bool, len(covered))
+    for hubID, count := range covered {
+        if count == len(demand) {
+            fulfilling[hubID] = true
+        }
+    }
+    return fulfilling, nil
+}
+
+// validateHubLocation makes sure a hub is created with usable coordinates,
+// nearest hub lookups and allocation rely on them
+func validateHubLocation(location datatypes.JSON) error {
+    lat, lng, ok := models.Hub{Location: location}.Coordinates()
+    if !ok {
+        return fmt.Errorf("location must have a numeric latitude and longitude")
+    }
+    if lat < -90 || lat > 90 {
+        return fmt.Errorf("latitude %f is out of range, must be between -90 and 90", lat)
+    }
+    if lng < -180 || lng > 180 {
+        return fmt.Errorf("longitude %f is out of range, must be between -180 and 180", lng)
+    }
+    return nil
+}
+
+// UpdateHub changes the name, location and attributes of a hub, nil or empty
+// values leave the field as it is
+func (s *HubService) UpdateHub(ctx context.Context, tenantID string, id int, name *string, location, attributes datatypes.JSON) (*models.Hub, error) {
+    logTag := "[HubService][UpdateHub]"
+    log.InfofWithContext(ctx, logTag+" updating hub %d", id)
+
+    hub, err := s.tenantHub(ctx, tenantID, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if name != nil {
+        hub.Name = *name
+    }
+    if len(location) > 0 {
+        if err := validateHubLocation(location); err != nil {
+            return nil, err
+        }
+        hub.Location = location
+    }
+    if len(attributes) > 0 {
+        hub.Attributes = attributes
+    }
+
+    if err := s.HubRepo.Update(ctx, hub); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to update hub %v", err)
+        return nil, fmt.Errorf("failed to update hub %w", err)
+    }
+
+    return hub, nil
+}
+
+// SetHubActive deactivates or reactivates a hub. An inactive hub stays
+// readable and keeps shipping what it holds, but takes no new stock and is
+// not allocated to
+func (s *HubService) SetHubActive(ctx context.Context, tenantID string, id int, active bool) (*models.Hub, error) {
+    logTag := "[HubService][SetHubActive]"
+    log.InfofWithContext(ctx, logTag+" setting hub %d active to %t", id, active)
+
+    hub, err := s.tenantHub(ctx, tenantID, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.HubRepo.SetActive(ctx, id, active); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to set hub active flag %v", err)
+        return nil, fmt.Errorf("failed to set hub active flag %w", err)
+    }
+
+    hub.Active = active
+    return hub, nil
+}
+
+func (s *HubService) DeleteHub(ctx context.Context, tenantID string, id int) error {
+    logTag := "[HubService][DeleteHub]"
+    log.InfofWithContext(ctx, logTag+" deleting hub %d", id)
+
+    if _, err := s.tenantHub(ctx, tenantID, id); err != nil {
+        return err
+    }
+
+    if err := s.HubRepo.Delete(ctx, id); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to delete hub %v", err)
+        return fmt.Errorf("failed to delete hub %w", err)
+    }
+
+    return nil
+}
+
+func (s *HubService) tenantHub(ctx context.Context, tenantID string, id int) (*models.Hub, error) {
+    hub, err := s.HubRepo.GetByID(ctx, uint(id))
+    if err != nil {
+        return nil, fmt.Errorf("failed to get Hub ID %w", err)
+    }
+    if hub.TenantID != tenantID {
+        return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, id, tenantID)
+    }
+    return hub, nil
+}
+
+// checkHubActive refuses new stock and new allocations at a deactivated hub
+func checkHubActive(hub *models.Hub) error {
+    if !hub.Active {
+        return fmt.Errorf("%w: hub %d", storage.ErrHubInactive, hub.ID)
+    }
+    return nil
+}
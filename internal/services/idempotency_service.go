@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type IdempotencyService struct {
+	IdempotencyRepo *storage.IdempotencyRepo
+	TTL             time.Duration
+	PendingTimeout  time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo *storage.IdempotencyRepo, ttl, pendingTimeout time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	if pendingTimeout <= 0 {
+		pendingTimeout = time.Minute
+	}
+	return &IdempotencyService{
+		IdempotencyRepo: idempotencyRepo,
+		TTL:             ttl,
+		PendingTimeout:  pendingTimeout,
+	}
+}
+
+// Begin claims the key for the request. It returns the stored record when
+// the request was already answered, nil when the caller has to run it and
+// report the outcome through Finish. A key still pending after the pending
+// timeout is taken over, the request holding it is assumed lost
+func (s *IdempotencyService) Begin(ctx context.Context, scope storage.IdempotencyScope, body []byte) (*models.IdempotencyKey, error) {
+	logTag := "[IdempotencyService][Begin]"
+	log.InfofWithContext(ctx, logTag+" claiming idempotency key %s for %s %s", scope.Key, scope.Method, scope.Path)
+
+	now := time.Now()
+	sum := sha256.Sum256(body)
+	record := &models.IdempotencyKey{
+		TenantID:    scope.TenantID,
+		Method:      scope.Method,
+		Path:        scope.Path,
+		Key:         scope.Key,
+		RequestHash: hex.EncodeToString(sum[:]),
+		ExpiresAt:   now.Add(s.TTL),
+	}
+
+	existing, err := s.IdempotencyRepo.Claim(ctx, record, now.Add(-s.PendingTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key %w", err)
+	}
+	if existing == nil {
+		return nil, nil
+	}
+
+	if existing.RequestHash != record.RequestHash {
+		return nil, fmt.Errorf("%w: %s", storage.ErrIdempotencyMismatch, scope.Key)
+	}
+	if existing.Status != models.IdempotencyStatusCompleted {
+		return nil, fmt.Errorf("%w: %s", storage.ErrIdempotencyKeyInUse, scope.Key)
+	}
+
+	log.InfofWithContext(ctx, logTag+" replaying response stored for idempotency key %s", scope.Key)
+	return existing, nil
+}
+
+// Finish stores the response for replays. Server errors release the key
+// instead, the change was not applied and the client may retry with it
+func (s *IdempotencyService) Finish(ctx context.Context, scope storage.IdempotencyScope, code int, headers map[string]string, body []byte) error {
+	logTag := "[IdempotencyService][Finish]"
+
+	if code >= 500 {
+		log.InfofWithContext(ctx, logTag+" releasing idempotency key %s after status %d", scope.Key, code)
+		return s.IdempotencyRepo.Release(ctx, scope)
+	}
+
+	return s.IdempotencyRepo.Complete(ctx, scope, code, headers, body)
+}
+
+// StartCleanupWorker periodically deletes keys past their window until ctx
+// is cancelled
+func (s *IdempotencyService) StartCleanupWorker(ctx context.Context, interval time.Duration) {
+	logTag := "[IdempotencyService][StartCleanupWorker]"
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := s.IdempotencyRepo.DeleteExpired(ctx, now)
+			if err != nil {
+				log.ErrorfWithContext(ctx, logTag+" failed to delete expired idempotency keys %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.InfofWithContext(ctx, logTag+" deleted %d expired idempotency keys", deleted)
+			}
+		}
+	}
+}
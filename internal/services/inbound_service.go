@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type InboundService struct {
+	InboundRepo *storage.InboundRepo
+	SKURepo     *storage.SKURepo
+	HubRepo     *storage.HubRepo
+}
+
+func NewInboundService(inboundRepo *storage.InboundRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo) *InboundService {
+	return &InboundService{
+		InboundRepo: inboundRepo,
+		SKURepo:     skuRepo,
+		HubRepo:     hubRepo,
+	}
+}
+
+func (s *InboundService) CreateInbound(ctx context.Context, tenantID, sellerID string, hubID int, inboundType, reference string, expectedAt *time.Time, lines []SKUQuantity) (*models.InboundOrder, error) {
+	logTag := "[InboundService][CreateInbound]"
+	log.InfofWithContext(ctx, logTag+" creating %s %s for hub %d", inboundType, reference, hubID)
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != tenantID {
+		return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+	}
+	if err := checkHubActive(hub); err != nil {
+		return nil, err
+	}
+
+	skus, err := resolveSKUs(ctx, s.SKURepo, tenantID, sellerID, lineCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+	for _, sku := range skus {
+		if err := checkSKUActive(sku); err != nil {
+			return nil, err
+		}
+	}
+
+	order := &models.InboundOrder{
+		TenantID:   tenantID,
+		SellerID:   sellerID,
+		HubID:      hubID,
+		Type:       inboundType,
+		Reference:  reference,
+		ExpectedAt: expectedAt,
+	}
+
+	merged := make(map[string]int)
+	for _, line := range lines {
+		if idx, ok := merged[line.SKUCode]; ok {
+			order.Lines[idx].ExpectedQuantity += line.Quantity
+			continue
+		}
+		merged[line.SKUCode] = len(order.Lines)
+		order.Lines = append(order.Lines, models.InboundOrderLine{
+			SKUID:            skus[line.SKUCode].ID,
+			SKUCode:          line.SKUCode,
+			ExpectedQuantity: line.Quantity,
+		})
+	}
+
+	if err := s.InboundRepo.Create(ctx, order); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create inbound order %v", err)
+		return nil, fmt.Errorf("failed to create inbound order %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" inbound order created successfully with ID: %d", order.ID)
+	return order, nil
+}
+
+func (s *InboundService) GetInbound(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundService][GetInbound]"
+	log.InfofWithContext(ctx, logTag+" fetching inbound order %d", id)
+
+	order, err := s.InboundRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch inbound order %v", err)
+		return nil, fmt.Errorf("failed to fetch inbound order %w", err)
+	}
+
+	return order, nil
+}
+
+// ReceiveInbound posts received quantities, skus that are not on the order
+// must still belong to its seller
+func (s *InboundService) ReceiveInbound(ctx context.Context, tenantID string, id int64, lines []SKUQuantity, info storage.MovementInfo) (*models.InboundOrder, error) {
+	logTag := "[InboundService][ReceiveInbound]"
+	log.InfofWithContext(ctx, logTag+" receiving inbound order %d", id)
+
+	order, err := s.InboundRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch inbound order %v", err)
+		return nil, fmt.Errorf("failed to fetch inbound order %w", err)
+	}
+
+	skuIDs := make(map[string]int, len(order.Lines))
+	for _, line := range order.Lines {
+		skuIDs[line.SKUCode] = line.SKUID
+	}
+
+	var unexpected []string
+	for _, line := range lines {
+		if _, ok := skuIDs[line.SKUCode]; !ok {
+			unexpected = append(unexpected, line.SKUCode)
+		}
+	}
+	if len(unexpected) > 0 {
+		extra, err := resolveSKUCodes(ctx, s.SKURepo, order.TenantID, order.SellerID, unexpected)
+		if err != nil {
+			return nil, err
+		}
+		for code, skuID := range extra {
+			skuIDs[code] = skuID
+		}
+	}
+
+	receipts := make([]storage.InboundReceipt, 0, len(lines))
+	for _, line := range lines {
+		receipts = append(receipts, storage.InboundReceipt{
+			SKUID:    skuIDs[line.SKUCode],
+			SKUCode:  line.SKUCode,
+			Quantity: line.Quantity,
+			Serials:  line.Serials,
+		})
+	}
+
+	order, err = s.InboundRepo.Receive(ctx, tenantID, id, receipts, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive inbound order %v", err)
+		return nil, fmt.Errorf("failed to receive inbound order %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *InboundService) CloseInbound(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundService][CloseInbound]"
+	log.InfofWithContext(ctx, logTag+" closing inbound order %d", id)
+
+	order, err := s.InboundRepo.Close(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to close inbound order %v", err)
+		return nil, fmt.Errorf("failed to close inbound order %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *InboundService) CancelInbound(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundService][CancelInbound]"
+	log.InfofWithContext(ctx, logTag+" cancelling inbound order %d", id)
+
+	order, err := s.InboundRepo.Cancel(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel inbound order %v", err)
+		return nil, fmt.Errorf("failed to cancel inbound order %w", err)
+	}
+
+	return order, nil
+}
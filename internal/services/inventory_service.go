@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/omniful/go_commons/log"
 	"github.com/singhJasvinder101/go_wms/internal/storage"
@@ -13,17 +15,19 @@ type InventoryService struct {
 	InventoryRepo *storage.InventoryRepo
 	SKURepo       *storage.SKURepo
 	HubRepo       *storage.HubRepo
+	MovementRepo  *storage.MovementRepo
 }
 
-func NewInventoryService(inventoryRepo *storage.InventoryRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo) *InventoryService {
+func NewInventoryService(inventoryRepo *storage.InventoryRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo, movementRepo *storage.MovementRepo) *InventoryService {
 	return &InventoryService{
 		InventoryRepo: inventoryRepo,
 		SKURepo:       skuRepo,
 		HubRepo:       hubRepo,
+		MovementRepo:  movementRepo,
 	}
 }
 
-func (s *InventoryService) CreateInventory(ctx context.Context, tenantId, sellerId string, skuCode string, hubId int, quantity int64) (*models.Inventory, error) {
+func (s *InventoryService) CreateInventory(ctx context.Context, tenantId, sellerId string, skuCode string, hubId int, quantity int64, info storage.MovementInfo) (*models.Inventory, error) {
 	logTag := "[InventoryService][CreateInventory]"
 	log.InfofWithContext(ctx, logTag+" creating inventory for hub %d, seller %s, SKU %s", tenantId, sellerId, skuCode)
 
@@ -34,10 +38,21 @@ func (s *InventoryService) CreateInventory(ctx context.Context, tenantId, seller
 	}
 
 	if len(skus) == 0 {
-		return nil, fmt.Errorf("SKU not found: %s", skuCode)
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
 	}
 	skuID := skus[0].ID
+	if err := checkSKUActive(skus[0]); err != nil {
+		return nil, err
+	}
 
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubId))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if err := checkHubActive(hub); err != nil {
+		return nil, err
+	}
 
 	inventory := &models.Inventory{
 		TenantID: tenantId,
@@ -47,7 +62,8 @@ func (s *InventoryService) CreateInventory(ctx context.Context, tenantId, seller
 		Quantity: quantity,
 	}
 
-	if err := s.InventoryRepo.Create(ctx, inventory); err != nil {
+	info.Reason = models.MovementReasonCreate
+	if _, err := s.InventoryRepo.Create(ctx, inventory, info); err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to create inventory in database %v", err)
 		return nil, fmt.Errorf("failed to create inventory %w", err)
 	}
@@ -56,7 +72,7 @@ func (s *InventoryService) CreateInventory(ctx context.Context, tenantId, seller
 	return inventory, nil
 }
 
-func (s *InventoryService) UpsertInventory(ctx context.Context, tenantID, sellerID string, skuCode string, hubId int, quantity int64) (*models.Inventory, error) {
+func (s *InventoryService) UpsertInventory(ctx context.Context, tenantID, sellerID string, skuCode string, hubId int, quantity int64, expectedVersion *int64, info storage.MovementInfo) (*models.Inventory, error) {
 	logTag := "[InventoryService][UpsertInventory]"
 	log.InfofWithContext(ctx, logTag+" upserting inventory for hub %d, seller %s, SKU %s", tenantID, sellerID, skuCode)
 
@@ -67,17 +83,23 @@ func (s *InventoryService) UpsertInventory(ctx context.Context, tenantID, seller
 	}
 
 	if len(skus) == 0 {
-		return nil, fmt.Errorf("SKU not found: %s", skuCode)
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
 	}
 	skuID := skus[0].ID
+	if err := checkSKUActive(skus[0]); err != nil {
+		return nil, err
+	}
 
 	log.InfofWithContext(ctx, logTag+" here is sku_id", skuID)
 
-	_, err = s.HubRepo.GetByID(ctx, uint(hubId))
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubId))
 	if err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
 		return nil, fmt.Errorf("failed to get Hub ID %w", err)
 	}
+	if err := checkHubActive(hub); err != nil {
+		return nil, err
+	}
 
 	inventory := &models.Inventory{
 		TenantID: tenantID,
@@ -87,7 +109,8 @@ func (s *InventoryService) UpsertInventory(ctx context.Context, tenantID, seller
 		Quantity: quantity,
 	}
 
-	if err := s.InventoryRepo.Upsert(ctx, inventory); err != nil {
+	info.Reason = models.MovementReasonUpsert
+	if _, err := s.InventoryRepo.Upsert(ctx, inventory, expectedVersion, info); err != nil {
 		log.ErrorfWithContext(ctx, logTag+" failed to upsert inventory in database: %v", err)
 		return nil, fmt.Errorf("failed to upsert inventory %w", err)
 	}
@@ -96,16 +119,422 @@ func (s *InventoryService) UpsertInventory(ctx context.Context, tenantID, seller
 	return inventory, nil
 }
 
-func (s *InventoryService) UpdateInventoryQuantity(ctx context.Context, hubID uint, sellerID string, skuID int, quantity int) error {
+func (s *InventoryService) UpdateInventoryQuantity(ctx context.Context, hubID uint, sellerID string, skuID int, quantity int, expectedVersion *int64, info storage.MovementInfo) (*models.Inventory, *models.InventoryMovement, error) {
 	logTag := "[InventoryService][UpdateInventoryQuantity]"
 	log.InfofWithContext(ctx, logTag+" updating inventory quantities for hub %d, seller %s", hubID, sellerID)
 
 
-	if err := s.InventoryRepo.UpdateQuantity(ctx, hubID, sellerID, skuID, int(quantity)); err != nil {
-		log.ErrorfWithContext(ctx, logTag+" failed to update inventory for SKU %s: %v", skuID, err)
-		return fmt.Errorf("failed to update inventory for SKU %s: %w", skuID, err)
+	if info.Reason == "" {
+		info.Reason = models.MovementReasonAdjustment
+	}
+
+	if quantity > 0 {
+		hub, err := s.HubRepo.GetByID(ctx, hubID)
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+			return nil, nil, fmt.Errorf("failed to get Hub ID %w", err)
+		}
+		if err := checkHubActive(hub); err != nil {
+			return nil, nil, err
+		}
+
+		sku, err := s.SKURepo.GetByID(ctx, skuID)
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get SKU %v", err)
+			return nil, nil, fmt.Errorf("failed to get SKU %w", err)
+		}
+		if err := checkSKUActive(*sku); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	inventory, movement, err := s.InventoryRepo.UpdateQuantity(ctx, hubID, sellerID, skuID, int(quantity), expectedVersion, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to update inventory for SKU %d: %v", skuID, err)
+		return nil, nil, fmt.Errorf("failed to update inventory for SKU %d: %w", skuID, err)
+	}
+	log.InfofWithContext(ctx, logTag+" updated inventory for SKU %d, quantity: %d", skuID, quantity)
+
+	return inventory, movement, nil
+}
+
+func (s *InventoryService) GetMovements(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, page, pageSize int) ([]models.InventoryMovement, int64, error) {
+	logTag := "[InventoryService][GetMovements]"
+	log.InfofWithContext(ctx, logTag+" fetching movements for hub %d, SKU %s", hubID, skuCode)
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, 0, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, 0, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	movements, total, err := s.MovementRepo.GetBySKUHub(ctx, skus[0].ID, hubID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch movements %v", err)
+		return nil, 0, fmt.Errorf("failed to fetch movements %w", err)
+	}
+
+	return movements, total, nil
+}
+
+// GetInventoryAsOf returns the on hand and sellable quantity per sku at the
+// hub as of the given timestamp, requested skus with no history by then
+// report zero
+func (s *InventoryService) GetInventoryAsOf(ctx context.Context, hubID int, sellerID string, skuCodes []string, asOf time.Time) ([]models.SKUQuantitySnapshot, error) {
+	logTag := "[InventoryService][GetInventoryAsOf]"
+	log.InfofWithContext(ctx, logTag+" fetching inventory for hub %d, seller %s as of %s", hubID, sellerID, asOf)
+
+	snapshots, err := s.MovementRepo.GetQuantitiesAsOf(ctx, hubID, sellerID, skuCodes, asOf)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch inventory as of timestamp %v", err)
+		return nil, fmt.Errorf("failed to fetch inventory as of timestamp %w", err)
+	}
+
+	found := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		found[snapshot.SKU] = true
+	}
+	for _, code := range skuCodes {
+		if !found[code] {
+			snapshots = append(snapshots, models.SKUQuantitySnapshot{SKU: code})
+			found[code] = true
+		}
+	}
+
+	return snapshots, nil
+}
+
+func (s *InventoryService) AdjustBinStock(ctx context.Context, tenantID, sellerID, skuCode string, hubID, locationID int, delta int64, info storage.MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryService][AdjustBinStock]"
+	log.InfofWithContext(ctx, logTag+" adjusting SKU %s in bin %d of hub %d by %d", skuCode, locationID, hubID, delta)
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	if delta > 0 {
+		hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+			return nil, fmt.Errorf("failed to get Hub ID %w", err)
+		}
+		if err := checkHubActive(hub); err != nil {
+			return nil, err
+		}
+		if err := checkSKUActive(skus[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	info.Reason = models.MovementReasonBinAdjustment
+	movement, err := s.InventoryRepo.AdjustBin(ctx, tenantID, sellerID, hubID, skus[0].ID, locationID, delta, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to adjust bin stock %v", err)
+		return nil, fmt.Errorf("failed to adjust bin stock %w", err)
+	}
+
+	return movement, nil
+}
+
+func (s *InventoryService) MoveBinStock(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, fromLocationID, toLocationID *int, quantity int64, info storage.MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryService][MoveBinStock]"
+	log.InfofWithContext(ctx, logTag+" moving %d of SKU %s in hub %d", quantity, skuCode, hubID)
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	movement, err := s.InventoryRepo.MoveBetweenBins(ctx, hubID, skus[0].ID, fromLocationID, toLocationID, quantity, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to move bin stock %v", err)
+		return nil, fmt.Errorf("failed to move bin stock %w", err)
+	}
+
+	return movement, nil
+}
+
+func (s *InventoryService) GetBinStock(ctx context.Context, hubID int, sellerID string, skuCodes []string, locationID int) ([]models.BinStock, error) {
+	logTag := "[InventoryService][GetBinStock]"
+	log.InfofWithContext(ctx, logTag+" fetching bin stock for hub %d, seller %s", hubID, sellerID)
+
+	stock, err := s.InventoryRepo.GetBinStock(ctx, hubID, sellerID, skuCodes, locationID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch bin stock %v", err)
+		return nil, fmt.Errorf("failed to fetch bin stock %w", err)
+	}
+
+	return stock, nil
+}
+
+// ReceiveLot books stock of a sku into the hub under a lot number, receiving
+// more of an existing lot tops it up and keeps its original dates
+func (s *InventoryService) ReceiveLot(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, lotNumber string, manufacturedAt, expiresAt *time.Time, quantity int64, info storage.MovementInfo) (*models.InventoryLot, *models.InventoryMovement, error) {
+	logTag := "[InventoryService][ReceiveLot]"
+	log.InfofWithContext(ctx, logTag+" receiving %d of SKU %s lot %s into hub %d", quantity, skuCode, lotNumber, hubID)
+
+	if manufacturedAt != nil && expiresAt != nil && !manufacturedAt.Before(*expiresAt) {
+		return nil, nil, fmt.Errorf("manufactured_at must be before expires_at")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: lot %s expired at %s", storage.ErrLotExpired, lotNumber, expiresAt.Format(time.RFC3339))
+	}
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if err := checkHubActive(hub); err != nil {
+		return nil, nil, err
+	}
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+	if err := checkSKUActive(skus[0]); err != nil {
+		return nil, nil, err
+	}
+
+	lot := &models.InventoryLot{
+		TenantID:       tenantID,
+		SellerID:       sellerID,
+		HubID:          hubID,
+		SKUID:          skus[0].ID,
+		LotNumber:      lotNumber,
+		ManufacturedAt: manufacturedAt,
+		ExpiresAt:      expiresAt,
+		Quantity:       quantity,
+	}
+
+	info.Reason = models.MovementReasonLotReceipt
+	movement, err := s.InventoryRepo.ReceiveLot(ctx, lot, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive lot %v", err)
+		return nil, nil, fmt.Errorf("failed to receive lot %w", err)
+	}
+
+	return lot, movement, nil
+}
+
+// MoveInventoryStatus moves units of a sku between condition buckets, only
+// sellable stock can be reserved or allocated
+func (s *InventoryService) MoveInventoryStatus(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, fromStatus, toStatus string, quantity int64, info storage.MovementInfo) (*models.Inventory, *models.InventoryMovement, error) {
+	logTag := "[InventoryService][MoveInventoryStatus]"
+	log.InfofWithContext(ctx, logTag+" moving %d of SKU %s in hub %d from %s to %s", quantity, skuCode, hubID, fromStatus, toStatus)
+
+	if fromStatus == toStatus {
+		return nil, nil, fmt.Errorf("from and to status must differ")
+	}
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	info.Reason = models.MovementReasonStatusChange
+	inventory, movement, err := s.InventoryRepo.MoveStatus(ctx, tenantID, sellerID, hubID, skus[0].ID, fromStatus, toStatus, quantity, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to move inventory status %v", err)
+		return nil, nil, fmt.Errorf("failed to move inventory status %w", err)
+	}
+
+	return inventory, movement, nil
+}
+
+// InventoryAdjustment is one line of a batch adjustment as sent by callers
+type InventoryAdjustment struct {
+	HubID    int
+	SellerID string
+	SKUCode  string
+	Delta    int64
+	Reason   string
+	Serials  []string
+}
+
+// AdjustInventoryBatch applies all lines or none of them, lines taking stock
+// below zero fail the batch unless allowNegative is set
+func (s *InventoryService) AdjustInventoryBatch(ctx context.Context, tenantID string, lines []InventoryAdjustment, allowNegative bool, info storage.MovementInfo) ([]models.AdjustmentResult, error) {
+	logTag := "[InventoryService][AdjustInventoryBatch]"
+	log.InfofWithContext(ctx, logTag+" adjusting %d lines for tenant %s", len(lines), tenantID)
+
+	hubs := make(map[int]*models.Hub)
+	codesBySeller := make(map[string][]string)
+	for _, line := range lines {
+		hub, ok := hubs[line.HubID]
+		if !ok {
+			var err error
+			hub, err = s.HubRepo.GetByID(ctx, uint(line.HubID))
+			if err != nil {
+				log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+				return nil, fmt.Errorf("failed to get Hub ID %w", err)
+			}
+			if hub.TenantID != tenantID {
+				return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, line.HubID, tenantID)
+			}
+			hubs[line.HubID] = hub
+		}
+		if line.Delta > 0 {
+			if err := checkHubActive(hub); err != nil {
+				return nil, err
+			}
+		}
+		codesBySeller[line.SellerID] = append(codesBySeller[line.SellerID], line.SKUCode)
+	}
+
+	skus := make(map[string]map[string]models.SKU, len(codesBySeller))
+	for sellerID, codes := range codesBySeller {
+		bySeller, err := resolveSKUs(ctx, s.SKURepo, tenantID, sellerID, codes)
+		if err != nil {
+			return nil, err
+		}
+		skus[sellerID] = bySeller
+	}
+
+	adjustments := make([]storage.StockAdjustment, 0, len(lines))
+	for _, line := range lines {
+		sku := skus[line.SellerID][line.SKUCode]
+		if line.Delta > 0 {
+			if err := checkSKUActive(sku); err != nil {
+				return nil, err
+			}
+		}
+
+		reason := line.Reason
+		if reason == "" {
+			reason = models.MovementReasonAdjustment
+		}
+		adjustments = append(adjustments, storage.StockAdjustment{
+			TenantID: tenantID,
+			SellerID: line.SellerID,
+			HubID:    line.HubID,
+			SKUID:    sku.ID,
+			SKUCode:  line.SKUCode,
+			Delta:    line.Delta,
+			Reason:   reason,
+			Serials:  line.Serials,
+		})
+	}
+
+	results, err := s.InventoryRepo.AdjustBatch(ctx, adjustments, allowNegative, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to apply batch adjustment %v", err)
+		return nil, fmt.Errorf("failed to apply batch adjustment %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" applied %d adjustment lines", len(results))
+	return results, nil
+}
+
+// ExportInventory streams the inventory of the tenant to fn, filter may
+// narrow it down to one hub and one seller
+func (s *InventoryService) ExportInventory(ctx context.Context, filter storage.InventoryExportFilter, fn func(*models.InventoryExportRow) error) error {
+	logTag := "[InventoryService][ExportInventory]"
+	log.InfofWithContext(ctx, logTag+" exporting inventory for tenant %s", filter.TenantID)
+
+	if filter.HubID != 0 {
+		hub, err := s.HubRepo.GetByID(ctx, uint(filter.HubID))
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+			return fmt.Errorf("failed to get Hub ID %w", err)
+		}
+		if hub.TenantID != filter.TenantID {
+			return fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, filter.HubID, filter.TenantID)
+		}
+	}
+
+	return s.InventoryRepo.StreamExport(ctx, filter, fn)
+}
+
+// GetNetworkATP answers how many units of each sku the seller can promise
+// across every active hub of the tenant, skus stocked nowhere come back with
+// a zero total. It reads the same per hub availability allocation does, hubs
+// oversold on paper count as zero
+func (s *InventoryService) GetNetworkATP(ctx context.Context, tenantID, sellerID string, skuCodes []string) ([]models.SKUAvailableToPromise, error) {
+	logTag := "[InventoryService][GetNetworkATP]"
+	log.InfofWithContext(ctx, logTag+" getting network ATP for seller %s", sellerID)
+
+	skuIDs, err := resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, skuCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(skuIDs))
+	for _, id := range skuIDs {
+		ids = append(ids, id)
+	}
+
+	availability, err := s.InventoryRepo.GetHubAvailability(ctx, tenantID, sellerID, ids)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get network availability %v", err)
+		return nil, fmt.Errorf("failed to get network availability %w", err)
+	}
+
+	hubs, err := s.HubRepo.GetActiveByTenant(ctx, tenantID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get active hubs %v", err)
+		return nil, fmt.Errorf("failed to get active hubs %w", err)
+	}
+	hubNames := make(map[int]string, len(hubs))
+	for _, hub := range hubs {
+		hubNames[hub.ID] = hub.Name
+	}
+
+	sort.Slice(availability, func(i, j int) bool {
+		if availability[i].SKUID != availability[j].SKUID {
+			return availability[i].SKUID < availability[j].SKUID
+		}
+		return availability[i].HubID < availability[j].HubID
+	})
+
+	bySKU := make(map[int][]models.NetworkAvailability, len(ids))
+	for _, row := range availability {
+		bySKU[row.SKUID] = append(bySKU[row.SKUID], models.NetworkAvailability{
+			HubID:     row.HubID,
+			HubName:   hubNames[row.HubID],
+			SKUID:     row.SKUID,
+			Available: max(row.Available, 0),
+		})
+	}
+
+	atp := make([]models.SKUAvailableToPromise, 0, len(skuCodes))
+	for _, code := range skuCodes {
+		line := models.SKUAvailableToPromise{
+			SKUID:   skuIDs[code],
+			SKUCode: code,
+			Hubs:    append([]models.NetworkAvailability{}, bySKU[skuIDs[code]]...),
+		}
+		for _, hub := range line.Hubs {
+			line.Total += hub.Available
+		}
+		atp = append(atp, line)
 	}
-	log.InfofWithContext(ctx, logTag+" updated inventory for SKU %s, quantity: %d", skuID, quantity)
 
-	return nil
+	return atp, nil
 }
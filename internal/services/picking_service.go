@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type PickingService struct {
+	PickingRepo *storage.PickingRepo
+	HubRepo     *storage.HubRepo
+	HoldTTL     time.Duration
+}
+
+func NewPickingService(pickingRepo *storage.PickingRepo, hubRepo *storage.HubRepo, holdTTL time.Duration) *PickingService {
+	return &PickingService{
+		PickingRepo: pickingRepo,
+		HubRepo:     hubRepo,
+		HoldTTL:     holdTTL,
+	}
+}
+
+// CreateWave batches the orders allocated to a hub into a pick wave, an empty
+// allocationIDs takes every waiting order and a zero maxOrders has no limit.
+// Orders whose hold lapsed are reserved again for another allocation hold
+func (s *PickingService) CreateWave(ctx context.Context, tenantID string, hubID int, allocationIDs []int64, maxOrders int, info storage.MovementInfo) (*models.PickWave, error) {
+	logTag := "[PickingService][CreateWave]"
+	log.InfofWithContext(ctx, logTag+" creating pick wave for hub %d", hubID)
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != tenantID {
+		return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+	}
+
+	wave := &models.PickWave{
+		TenantID: tenantID,
+		HubID:    hubID,
+	}
+
+	if err := s.PickingRepo.CreateWave(ctx, wave, allocationIDs, maxOrders, time.Now().Add(s.HoldTTL), info); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create pick wave %v", err)
+		return nil, fmt.Errorf("failed to create pick wave %w", err)
+	}
+
+	if len(wave.Unreserved) > 0 {
+		log.InfofWithContext(ctx, logTag+" %d lines left out of wave %d, their reservation could not be held again", len(wave.Unreserved), wave.ID)
+	}
+	log.InfofWithContext(ctx, logTag+" pick wave created successfully with ID: %d", wave.ID)
+	return wave, nil
+}
+
+func (s *PickingService) GetWave(ctx context.Context, tenantID string, id int64) (*models.PickWave, error) {
+	logTag := "[PickingService][GetWave]"
+	log.InfofWithContext(ctx, logTag+" fetching pick wave %d", id)
+
+	wave, err := s.PickingRepo.GetWave(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch pick wave %v", err)
+		return nil, fmt.Errorf("failed to fetch pick wave %w", err)
+	}
+
+	return wave, nil
+}
+
+// ConfirmPick records the picked quantity of a task, anything less than the
+// task asked for is a short pick
+func (s *PickingService) ConfirmPick(ctx context.Context, tenantID string, taskID int64, picked int64, info storage.MovementInfo) (*models.PickTask, error) {
+	logTag := "[PickingService][ConfirmPick]"
+	log.InfofWithContext(ctx, logTag+" confirming %d picked for task %d", picked, taskID)
+
+	task, err := s.PickingRepo.ConfirmPick(ctx, tenantID, taskID, picked, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to confirm pick %v", err)
+		return nil, fmt.Errorf("failed to confirm pick %w", err)
+	}
+
+	return task, nil
+}
+
+func (s *PickingService) CancelWave(ctx context.Context, tenantID string, id int64) (*models.PickWave, error) {
+	logTag := "[PickingService][CancelWave]"
+	log.InfofWithContext(ctx, logTag+" cancelling pick wave %d", id)
+
+	wave, err := s.PickingRepo.CancelWave(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel pick wave %v", err)
+		return nil, fmt.Errorf("failed to cancel pick wave %w", err)
+	}
+
+	return wave, nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type PutawayService struct {
+	InventoryRepo *storage.InventoryRepo
+	HubRepo       *storage.HubRepo
+	SKURepo       *storage.SKURepo
+}
+
+func NewPutawayService(inventoryRepo *storage.InventoryRepo, hubRepo *storage.HubRepo, skuRepo *storage.SKURepo) *PutawayService {
+	return &PutawayService{
+		InventoryRepo: inventoryRepo,
+		HubRepo:       hubRepo,
+		SKURepo:       skuRepo,
+	}
+}
+
+// putawayRules are read from the attributes of a location and apply to every
+// bin below it, {"putaway": false} keeps stock out and {"categories": [...]}
+// only admits skus whose metadata category is listed
+type putawayRules struct {
+	Putaway    *bool    `json:"putaway"`
+	Categories []string `json:"categories"`
+}
+
+// putawayBin is a bin that may take the sku along with its current fill
+type putawayBin struct {
+	location    models.HubLocation
+	zoneCode    string
+	skuQuantity int64
+	free        *int64
+}
+
+// SuggestPutaway spreads quantity arriving units of a sku over the bins of a
+// hub. Bins already holding the sku come first so stock stays together, then
+// the bins with the most room left
+func (s *PutawayService) SuggestPutaway(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, quantity int64) (*models.PutawayPlan, error) {
+	logTag := "[PutawayService][SuggestPutaway]"
+	log.InfofWithContext(ctx, logTag+" suggesting putaway of %d of SKU %s in hub %d", quantity, skuCode, hubID)
+
+	sku, err := s.getSKU(ctx, tenantID, sellerID, skuCode)
+	if err != nil {
+		return nil, err
+	}
+
+	bins, err := s.eligibleBins(ctx, hubID, sellerID, sku)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get eligible bins %v", err)
+		return nil, err
+	}
+
+	candidates := make([]putawayBin, 0, len(bins))
+	for _, bin := range bins {
+		if bin.free != nil && *bin.free <= 0 {
+			continue
+		}
+		candidates = append(candidates, bin)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if (a.skuQuantity > 0) != (b.skuQuantity > 0) {
+			return a.skuQuantity > 0
+		}
+		switch {
+		case a.free == nil && b.free != nil:
+			return true
+		case a.free != nil && b.free == nil:
+			return false
+		case a.free != nil && *a.free != *b.free:
+			return *a.free > *b.free
+		}
+		return a.location.Code < b.location.Code
+	})
+
+	plan := &models.PutawayPlan{
+		SKU:         skuCode,
+		HubID:       hubID,
+		Quantity:    quantity,
+		Suggestions: []models.PutawaySuggestion{},
+	}
+
+	remaining := quantity
+	for _, bin := range candidates {
+		if remaining == 0 {
+			break
+		}
+
+		take := remaining
+		if bin.free != nil {
+			take = min(take, *bin.free)
+		}
+
+		reason := models.PutawayReasonFreeCapacity
+		if bin.skuQuantity > 0 {
+			reason = models.PutawayReasonConsolidate
+		}
+
+		plan.Suggestions = append(plan.Suggestions, models.PutawaySuggestion{
+			LocationID:   bin.location.ID,
+			LocationCode: bin.location.Code,
+			ZoneCode:     bin.zoneCode,
+			Quantity:     take,
+			SKUQuantity:  bin.skuQuantity,
+			FreeCapacity: bin.free,
+			Reason:       reason,
+		})
+		remaining -= take
+	}
+	plan.Unplaced = remaining
+
+	return plan, nil
+}
+
+// ConfirmPutaway moves unassigned units of the sku into the chosen bin, the
+// bin has to pass the same rules the suggestions are built from
+func (s *PutawayService) ConfirmPutaway(ctx context.Context, tenantID, sellerID, skuCode string, hubID, locationID int, quantity int64, info storage.MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[PutawayService][ConfirmPutaway]"
+	log.InfofWithContext(ctx, logTag+" putting away %d of SKU %s into bin %d of hub %d", quantity, skuCode, locationID, hubID)
+
+	sku, err := s.getSKU(ctx, tenantID, sellerID, skuCode)
+	if err != nil {
+		return nil, err
+	}
+
+	bins, err := s.eligibleBins(ctx, hubID, sellerID, sku)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get eligible bins %v", err)
+		return nil, err
+	}
+	if _, ok := bins[locationID]; !ok {
+		return nil, fmt.Errorf("bin %d of hub %d does not accept putaway of SKU %s", locationID, hubID, skuCode)
+	}
+
+	if info.Reference == "" {
+		info.Reference = "putaway"
+	}
+
+	movement, err := s.InventoryRepo.PutawayToBin(ctx, hubID, sku.ID, locationID, quantity, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to put stock away %v", err)
+		return nil, fmt.Errorf("failed to put stock away %w", err)
+	}
+
+	return movement, nil
+}
+
+func (s *PutawayService) getSKU(ctx context.Context, tenantID, sellerID, skuCode string) (*models.SKU, error) {
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+	return &skus[0], nil
+}
+
+// eligibleBins returns the bins of the hub, keyed by id, that the rules of
+// the bin and all of its ancestors allow the sku into
+func (s *PutawayService) eligibleBins(ctx context.Context, hubID int, sellerID string, sku *models.SKU) (map[int]putawayBin, error) {
+	if _, err := s.HubRepo.GetByID(ctx, uint(hubID)); err != nil {
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+
+	locations, err := s.HubRepo.GetLocations(ctx, hubID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locations %w", err)
+	}
+
+	occupancy, err := s.InventoryRepo.GetBinOccupancy(ctx, hubID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin occupancy %w", err)
+	}
+
+	stock, err := s.InventoryRepo.GetBinStock(ctx, hubID, sellerID, []string{sku.SKUCode}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bin stock %w", err)
+	}
+	skuStock := make(map[int]int64, len(stock))
+	for _, row := range stock {
+		skuStock[row.LocationID] += row.Quantity
+	}
+
+	var meta struct {
+		Category string `json:"category"`
+	}
+	if len(sku.MetaData) > 0 {
+		_ = json.Unmarshal(sku.MetaData, &meta)
+	}
+
+	byID := make(map[int]models.HubLocation, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	bins := make(map[int]putawayBin)
+	for _, location := range locations {
+		if location.Type != models.LocationTypeBin {
+			continue
+		}
+
+		allowed := true
+		zoneCode := ""
+		for node, ok := location, true; ok; node, ok = parentOf(byID, node) {
+			if node.Type == models.LocationTypeZone {
+				zoneCode = node.Code
+			}
+
+			var rules putawayRules
+			if len(node.Attributes) > 0 {
+				_ = json.Unmarshal(node.Attributes, &rules)
+			}
+			if rules.Putaway != nil && !*rules.Putaway {
+				allowed = false
+			}
+			if len(rules.Categories) > 0 && !slices.Contains(rules.Categories, meta.Category) {
+				allowed = false
+			}
+		}
+		if !allowed {
+			continue
+		}
+
+		bin := putawayBin{
+			location:    location,
+			zoneCode:    zoneCode,
+			skuQuantity: skuStock[location.ID],
+		}
+		if location.Capacity != nil {
+			free := *location.Capacity - occupancy[location.ID]
+			bin.free = &free
+		}
+		bins[location.ID] = bin
+	}
+
+	return bins, nil
+}
+
+func parentOf(byID map[int]models.HubLocation, location models.HubLocation) (models.HubLocation, bool) {
+	if location.ParentID == nil {
+		return models.HubLocation{}, false
+	}
+	parent, ok := byID[*location.ParentID]
+	return parent, ok
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/internal/types"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type ReplenishmentService struct {
+	MovementRepo *storage.MovementRepo
+	HubRepo      *storage.HubRepo
+	// Defaults fills in window, lead time and safety stock a request leaves out
+	Defaults types.ReplenishmentConfig
+}
+
+func NewReplenishmentService(movementRepo *storage.MovementRepo, hubRepo *storage.HubRepo, defaults types.ReplenishmentConfig) *ReplenishmentService {
+	return &ReplenishmentService{
+		MovementRepo: movementRepo,
+		HubRepo:      hubRepo,
+		Defaults:     defaults,
+	}
+}
+
+// GetReplenishmentReport averages the daily outbound of every sku over the
+// window, projects how many days the current quantity lasts at that rate and
+// suggests topping up to lead time plus safety stock worth of demand. Zero
+// values for the day counts take the configured defaults
+func (s *ReplenishmentService) GetReplenishmentReport(ctx context.Context, filter storage.ReplenishmentFilter, windowDays, leadTimeDays, safetyStockDays int) (*models.ReplenishmentReport, error) {
+	logTag := "[ReplenishmentService][GetReplenishmentReport]"
+	log.InfofWithContext(ctx, logTag+" building replenishment report for tenant %s", filter.TenantID)
+
+	if windowDays <= 0 {
+		windowDays = s.Defaults.WindowDays
+	}
+	if leadTimeDays <= 0 {
+		leadTimeDays = s.Defaults.LeadTimeDays
+	}
+	if safetyStockDays <= 0 {
+		safetyStockDays = s.Defaults.SafetyStockDays
+	}
+	if windowDays <= 0 {
+		return nil, fmt.Errorf("window_days must be positive")
+	}
+
+	if filter.HubID != 0 {
+		hub, err := s.HubRepo.GetByID(ctx, uint(filter.HubID))
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+			return nil, fmt.Errorf("failed to get Hub ID %w", err)
+		}
+		if hub.TenantID != filter.TenantID {
+			return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, filter.HubID, filter.TenantID)
+		}
+	}
+
+	since := time.Now().AddDate(0, 0, -windowDays)
+	lines, err := s.MovementRepo.GetOutboundVelocity(ctx, filter, since)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get outbound velocity %v", err)
+		return nil, fmt.Errorf("failed to get outbound velocity %w", err)
+	}
+
+	for i := range lines {
+		suggestReplenishment(&lines[i], windowDays, leadTimeDays+safetyStockDays)
+	}
+
+	return &models.ReplenishmentReport{
+		WindowDays:      windowDays,
+		LeadTimeDays:    leadTimeDays,
+		SafetyStockDays: safetyStockDays,
+		Lines:           lines,
+	}, nil
+}
+
+// suggestReplenishment fills in the velocity, cover and suggested top up of a
+// line from its outbound over windowDays, enough to last coverDays
+func suggestReplenishment(line *models.ReplenishmentLine, windowDays, coverDays int) {
+	line.AvgDailyOutbound = float64(line.OutboundUnits) / float64(windowDays)
+	if line.AvgDailyOutbound > 0 {
+		cover := float64(max(line.Quantity, 0)) / line.AvgDailyOutbound
+		line.DaysOfCover = &cover
+	}
+	line.TargetQuantity = int64(math.Ceil(line.AvgDailyOutbound * float64(coverDays)))
+	line.SuggestedQuantity = max(line.TargetQuantity-line.Quantity, 0)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+func TestSuggestReplenishment(t *testing.T) {
+	days := func(d float64) *float64 { return &d }
+
+	tests := []struct {
+		name          string
+		quantity      int64
+		outbound      int64
+		windowDays    int
+		coverDays     int
+		wantAvg       float64
+		wantCover     *float64
+		wantTarget    int64
+		wantSuggested int64
+	}{
+		{
+			name:          "tops up to the cover target",
+			quantity:      20,
+			outbound:      280,
+			windowDays:    28,
+			coverDays:     10,
+			wantAvg:       10,
+			wantCover:     days(2),
+			wantTarget:    100,
+			wantSuggested: 80,
+		},
+		{
+			name:          "partial units round the target up",
+			quantity:      0,
+			outbound:      10,
+			windowDays:    28,
+			coverDays:     10,
+			wantAvg:       10.0 / 28,
+			wantCover:     days(0),
+			wantTarget:    4,
+			wantSuggested: 4,
+		},
+		{
+			name:          "stock above the target needs nothing",
+			quantity:      500,
+			outbound:      280,
+			windowDays:    28,
+			coverDays:     10,
+			wantAvg:       10,
+			wantCover:     days(50),
+			wantTarget:    100,
+			wantSuggested: 0,
+		},
+		{
+			name:          "no outbound has no cover and no suggestion",
+			quantity:      5,
+			outbound:      0,
+			windowDays:    28,
+			coverDays:     10,
+			wantAvg:       0,
+			wantCover:     nil,
+			wantTarget:    0,
+			wantSuggested: 0,
+		},
+		{
+			name:          "oversold stock has zero cover and tops up past it",
+			quantity:      -5,
+			outbound:      70,
+			windowDays:    7,
+			coverDays:     3,
+			wantAvg:       10,
+			wantCover:     days(0),
+			wantTarget:    30,
+			wantSuggested: 35,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := models.ReplenishmentLine{Quantity: tt.quantity, OutboundUnits: tt.outbound}
+			suggestReplenishment(&line, tt.windowDays, tt.coverDays)
+
+			if line.AvgDailyOutbound != tt.wantAvg {
+				t.Errorf("avg daily outbound = %v, want %v", line.AvgDailyOutbound, tt.wantAvg)
+			}
+			switch {
+			case (line.DaysOfCover == nil) != (tt.wantCover == nil):
+				t.Errorf("days of cover = %v, want %v", line.DaysOfCover, tt.wantCover)
+			case line.DaysOfCover != nil && *line.DaysOfCover != *tt.wantCover:
+				t.Errorf("days of cover = %v, want %v", *line.DaysOfCover, *tt.wantCover)
+			}
+			if line.TargetQuantity != tt.wantTarget {
+				t.Errorf("target = %d, want %d", line.TargetQuantity, tt.wantTarget)
+			}
+			if line.SuggestedQuantity != tt.wantSuggested {
+				t.Errorf("suggested = %d, want %d", line.SuggestedQuantity, tt.wantSuggested)
+			}
+		})
+	}
+}
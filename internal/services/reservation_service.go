@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type ReservationService struct {
+	ReservationRepo *storage.ReservationRepo
+	SKURepo         *storage.SKURepo
+	HubRepo         *storage.HubRepo
+	DefaultTTL      time.Duration
+}
+
+func NewReservationService(reservationRepo *storage.ReservationRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo, defaultTTL time.Duration) *ReservationService {
+	return &ReservationService{
+		ReservationRepo: reservationRepo,
+		SKURepo:         skuRepo,
+		HubRepo:         hubRepo,
+		DefaultTTL:      defaultTTL,
+	}
+}
+
+// Reserve holds quantity units of skuCode at hubID for orderRef, a zero ttl
+// falls back to the configured default
+func (s *ReservationService) Reserve(ctx context.Context, tenantID, sellerID, skuCode string, hubID int, orderRef string, quantity int64, ttl time.Duration, info storage.MovementInfo) (*models.InventoryReservation, error) {
+	logTag := "[ReservationService][Reserve]"
+	log.InfofWithContext(ctx, logTag+" reserving %d of SKU %s at hub %d for order %s", quantity, skuCode, hubID, orderRef)
+
+	skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if err := checkHubActive(hub); err != nil {
+		return nil, err
+	}
+
+	if ttl <= 0 {
+		ttl = s.DefaultTTL
+	}
+
+	reservation := &models.InventoryReservation{
+		TenantID:  tenantID,
+		SellerID:  sellerID,
+		HubID:     hubID,
+		SKUID:     skus[0].ID,
+		OrderRef:  orderRef,
+		Quantity:  quantity,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.ReservationRepo.Reserve(ctx, reservation, info); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to reserve inventory %v", err)
+		return nil, fmt.Errorf("failed to reserve inventory %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" reservation created successfully with ID: %d", reservation.ID)
+	return reservation, nil
+}
+
+func (s *ReservationService) Release(ctx context.Context, tenantID string, id int64, info storage.MovementInfo) (*models.InventoryReservation, error) {
+	logTag := "[ReservationService][Release]"
+	log.InfofWithContext(ctx, logTag+" releasing reservation %d", id)
+
+	reservation, err := s.ReservationRepo.Release(ctx, tenantID, id, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to release reservation %v", err)
+		return nil, fmt.Errorf("failed to release reservation %w", err)
+	}
+
+	return reservation, nil
+}
+
+func (s *ReservationService) Commit(ctx context.Context, tenantID string, id int64, info storage.MovementInfo) (*models.InventoryReservation, error) {
+	logTag := "[ReservationService][Commit]"
+	log.InfofWithContext(ctx, logTag+" committing reservation %d", id)
+
+	reservation, err := s.ReservationRepo.Commit(ctx, tenantID, id, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to commit reservation %v", err)
+		return nil, fmt.Errorf("failed to commit reservation %w", err)
+	}
+
+	return reservation, nil
+}
+
+func (s *ReservationService) GetReservation(ctx context.Context, tenantID string, id int64) (*models.InventoryReservation, error) {
+	logTag := "[ReservationService][GetReservation]"
+	log.InfofWithContext(ctx, logTag+" fetching reservation %d", id)
+
+	reservation, err := s.ReservationRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch reservation %v", err)
+		return nil, fmt.Errorf("failed to fetch reservation %w", err)
+	}
+
+	return reservation, nil
+}
+
+func (s *ReservationService) GetOrderReservations(ctx context.Context, tenantID, orderRef string) ([]models.InventoryReservation, error) {
+	logTag := "[ReservationService][GetOrderReservations]"
+	log.InfofWithContext(ctx, logTag+" fetching reservations for order %s", orderRef)
+
+	reservations, err := s.ReservationRepo.GetByOrderRef(ctx, tenantID, orderRef)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch reservations %v", err)
+		return nil, fmt.Errorf("failed to fetch reservations %w", err)
+	}
+
+	return reservations, nil
+}
+
+// StartExpiryWorker periodically marks held reservations past their ttl as
+// expired until ctx is cancelled
+func (s *ReservationService) StartExpiryWorker(ctx context.Context, interval time.Duration) {
+	logTag := "[ReservationService][StartExpiryWorker]"
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := s.ReservationRepo.ExpireStale(ctx, now)
+			if err != nil {
+				log.ErrorfWithContext(ctx, logTag+" failed to expire reservations %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.InfofWithContext(ctx, logTag+" expired %d reservations", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+// ReturnDisposition is the fate of received units of a sku as sent by callers
+type ReturnDisposition struct {
+	SKUCode     string
+	Disposition string
+	Quantity    int64
+	Serials     []string
+	Note        string
+}
+
+type ReturnService struct {
+	ReturnRepo *storage.ReturnRepo
+	SKURepo    *storage.SKURepo
+	HubRepo    *storage.HubRepo
+}
+
+func NewReturnService(returnRepo *storage.ReturnRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo) *ReturnService {
+	return &ReturnService{
+		ReturnRepo: returnRepo,
+		SKURepo:    skuRepo,
+		HubRepo:    hubRepo,
+	}
+}
+
+func (s *ReturnService) CreateReturn(ctx context.Context, tenantID, sellerID, reference, orderRef, reason, actor string, lines []SKUQuantity) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][CreateReturn]"
+	log.InfofWithContext(ctx, logTag+" creating return %s", reference)
+
+	skuIDs, err := resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, lineCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+
+	rma := &models.ReturnAuthorization{
+		TenantID:  tenantID,
+		SellerID:  sellerID,
+		Reference: reference,
+		OrderRef:  orderRef,
+		Reason:    reason,
+	}
+
+	merged := make(map[string]int)
+	for _, line := range lines {
+		if idx, ok := merged[line.SKUCode]; ok {
+			rma.Lines[idx].AuthorizedQuantity += line.Quantity
+			continue
+		}
+		merged[line.SKUCode] = len(rma.Lines)
+		rma.Lines = append(rma.Lines, models.ReturnLine{
+			SKUID:              skuIDs[line.SKUCode],
+			SKUCode:            line.SKUCode,
+			AuthorizedQuantity: line.Quantity,
+		})
+	}
+
+	if err := s.ReturnRepo.Create(ctx, rma, actor); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create return %v", err)
+		return nil, fmt.Errorf("failed to create return %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" return created successfully with ID: %d", rma.ID)
+	return s.GetReturn(ctx, tenantID, rma.ID)
+}
+
+func (s *ReturnService) GetReturn(ctx context.Context, tenantID string, id int64) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][GetReturn]"
+	log.InfofWithContext(ctx, logTag+" fetching return %d", id)
+
+	rma, err := s.ReturnRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch return %v", err)
+		return nil, fmt.Errorf("failed to fetch return %w", err)
+	}
+
+	return rma, nil
+}
+
+// ReceiveReturn books units arriving at a hub of the return's tenant
+func (s *ReturnService) ReceiveReturn(ctx context.Context, tenantID string, id int64, hubID int, lines []SKUQuantity, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][ReceiveReturn]"
+	log.InfofWithContext(ctx, logTag+" receiving return %d at hub %d", id, hubID)
+
+	rma, err := s.GetReturn(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != rma.TenantID {
+		return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, rma.TenantID)
+	}
+
+	skuIDs, err := returnSKUIDs(rma, lineCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]storage.ReturnReceipt, 0, len(lines))
+	for _, line := range lines {
+		receipts = append(receipts, storage.ReturnReceipt{
+			SKUID:    skuIDs[line.SKUCode],
+			SKUCode:  line.SKUCode,
+			Quantity: line.Quantity,
+		})
+	}
+
+	if _, err := s.ReturnRepo.Receive(ctx, tenantID, id, hubID, receipts, actor); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive return %v", err)
+		return nil, fmt.Errorf("failed to receive return %w", err)
+	}
+
+	return s.GetReturn(ctx, tenantID, id)
+}
+
+// DisposeReturn posts the disposition of inspected units
+func (s *ReturnService) DisposeReturn(ctx context.Context, tenantID string, id int64, dispositions []ReturnDisposition, info storage.MovementInfo) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][DisposeReturn]"
+	log.InfofWithContext(ctx, logTag+" disposing units of return %d", id)
+
+	rma, err := s.GetReturn(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(dispositions))
+	for _, disposition := range dispositions {
+		codes = append(codes, disposition.SKUCode)
+	}
+	skuIDs, err := returnSKUIDs(rma, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]storage.ReturnDisposition, 0, len(dispositions))
+	for _, disposition := range dispositions {
+		items = append(items, storage.ReturnDisposition{
+			SKUID:       skuIDs[disposition.SKUCode],
+			SKUCode:     disposition.SKUCode,
+			Disposition: disposition.Disposition,
+			Quantity:    disposition.Quantity,
+			Serials:     disposition.Serials,
+			Note:        disposition.Note,
+		})
+	}
+
+	if _, err := s.ReturnRepo.Dispose(ctx, tenantID, id, items, info); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to dispose return units %v", err)
+		return nil, fmt.Errorf("failed to dispose return units %w", err)
+	}
+
+	return s.GetReturn(ctx, tenantID, id)
+}
+
+func (s *ReturnService) CompleteReturn(ctx context.Context, tenantID string, id int64, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][CompleteReturn]"
+	log.InfofWithContext(ctx, logTag+" completing return %d", id)
+
+	if _, err := s.ReturnRepo.Complete(ctx, tenantID, id, actor); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to complete return %v", err)
+		return nil, fmt.Errorf("failed to complete return %w", err)
+	}
+
+	return s.GetReturn(ctx, tenantID, id)
+}
+
+func (s *ReturnService) CancelReturn(ctx context.Context, tenantID string, id int64, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnService][CancelReturn]"
+	log.InfofWithContext(ctx, logTag+" cancelling return %d", id)
+
+	if _, err := s.ReturnRepo.Cancel(ctx, tenantID, id, actor); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel return %v", err)
+		return nil, fmt.Errorf("failed to cancel return %w", err)
+	}
+
+	return s.GetReturn(ctx, tenantID, id)
+}
+
+// returnSKUIDs maps codes to the skus authorized on the return, units of
+// any other sku are not accepted
+func returnSKUIDs(rma *models.ReturnAuthorization, codes []string) (map[string]int, error) {
+	authorized := make(map[string]int, len(rma.Lines))
+	for _, line := range rma.Lines {
+		authorized[line.SKUCode] = line.SKUID
+	}
+
+	for _, code := range codes {
+		if _, ok := authorized[code]; !ok {
+			return nil, fmt.Errorf("SKU %s is not on return %s", code, rma.Reference)
+		}
+	}
+
+	return authorized, nil
+}
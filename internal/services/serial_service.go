@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type SerialService struct {
+	SerialRepo *storage.SerialRepo
+}
+
+func NewSerialService(serialRepo *storage.SerialRepo) *SerialService {
+	return &SerialService{
+		SerialRepo: serialRepo,
+	}
+}
+
+func (s *SerialService) GetSerial(ctx context.Context, tenantID, serialNumber string) ([]models.SerialHistory, error) {
+	logTag := "[SerialService][GetSerial]"
+	log.InfofWithContext(ctx, logTag+" fetching serial %s for tenant %s", serialNumber, tenantID)
+
+	serials, err := s.SerialRepo.GetBySerialNumber(ctx, tenantID, serialNumber)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch serial %v", err)
+		return nil, fmt.Errorf("failed to fetch serial %w", err)
+	}
+
+	return serials, nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type ShipmentService struct {
+	ShipmentRepo   *storage.ShipmentRepo
+	AllocationRepo *storage.AllocationRepo
+	SKURepo        *storage.SKURepo
+	HubRepo        *storage.HubRepo
+}
+
+func NewShipmentService(shipmentRepo *storage.ShipmentRepo, allocationRepo *storage.AllocationRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo) *ShipmentService {
+	return &ShipmentService{
+		ShipmentRepo:   shipmentRepo,
+		AllocationRepo: allocationRepo,
+		SKURepo:        skuRepo,
+		HubRepo:        hubRepo,
+	}
+}
+
+// CreateShipment opens a shipment for the part of an allocated order that a
+// hub ships
+func (s *ShipmentService) CreateShipment(ctx context.Context, tenantID string, allocationID int64, hubID int, carrier string) (*models.Shipment, error) {
+	logTag := "[ShipmentService][CreateShipment]"
+	log.InfofWithContext(ctx, logTag+" creating %s shipment for allocation %d at hub %d", carrier, allocationID, hubID)
+
+	allocation, err := s.AllocationRepo.GetByID(ctx, tenantID, allocationID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get order allocation %v", err)
+		return nil, fmt.Errorf("failed to get order allocation %w", err)
+	}
+
+	shipsFromHub := false
+	for _, line := range allocation.Lines {
+		if line.HubID != nil && *line.HubID == hubID {
+			shipsFromHub = true
+			break
+		}
+	}
+	if !shipsFromHub {
+		return nil, fmt.Errorf("order %s is not allocated to hub %d", allocation.OrderRef, hubID)
+	}
+
+	shipment := &models.Shipment{
+		TenantID:     tenantID,
+		SellerID:     allocation.SellerID,
+		HubID:        hubID,
+		AllocationID: allocationID,
+		OrderRef:     allocation.OrderRef,
+		Carrier:      carrier,
+	}
+
+	if err := s.ShipmentRepo.Create(ctx, shipment); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create shipment %v", err)
+		return nil, fmt.Errorf("failed to create shipment %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" shipment created successfully with ID: %d", shipment.ID)
+	return shipment, nil
+}
+
+func (s *ShipmentService) GetShipment(ctx context.Context, tenantID string, id int64) (*models.Shipment, error) {
+	logTag := "[ShipmentService][GetShipment]"
+	log.InfofWithContext(ctx, logTag+" fetching shipment %d", id)
+
+	shipment, err := s.ShipmentRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch shipment %v", err)
+		return nil, fmt.Errorf("failed to fetch shipment %w", err)
+	}
+
+	return shipment, nil
+}
+
+func (s *ShipmentService) AddCarton(ctx context.Context, tenantID string, shipmentID int64, code string) (*models.Carton, error) {
+	logTag := "[ShipmentService][AddCarton]"
+	log.InfofWithContext(ctx, logTag+" adding carton to shipment %d", shipmentID)
+
+	carton, err := s.ShipmentRepo.AddCarton(ctx, tenantID, shipmentID, code)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to add carton %v", err)
+		return nil, fmt.Errorf("failed to add carton %w", err)
+	}
+
+	return carton, nil
+}
+
+// PackItem scans units of an ordered sku into a carton of the shipment
+func (s *ShipmentService) PackItem(ctx context.Context, tenantID string, shipmentID, cartonID int64, skuCode string, quantity int64, serials []string) (*models.Carton, error) {
+	logTag := "[ShipmentService][PackItem]"
+	log.InfofWithContext(ctx, logTag+" packing %d of SKU %s into carton %d", quantity, skuCode, cartonID)
+
+	shipment, err := s.ShipmentRepo.GetByID(ctx, tenantID, shipmentID)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get shipment %v", err)
+		return nil, fmt.Errorf("failed to get shipment %w", err)
+	}
+
+	inShipment := false
+	for _, carton := range shipment.Cartons {
+		if carton.ID == cartonID {
+			inShipment = true
+			break
+		}
+	}
+	if !inShipment {
+		return nil, fmt.Errorf("%w: carton %d is not part of shipment %d", storage.ErrCartonNotFound, cartonID, shipmentID)
+	}
+
+	skus, err := s.SKURepo.GetByCodes(ctx, shipment.TenantID, shipment.SellerID, []string{skuCode})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get SKU ID %v", err)
+		return nil, fmt.Errorf("failed to get SKU ID %w", err)
+	}
+	if len(skus) == 0 {
+		return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+	}
+
+	carton, err := s.ShipmentRepo.PackItem(ctx, tenantID, cartonID, storage.PackedItem{
+		SKUID:      skus[0].ID,
+		SKUCode:    skuCode,
+		Quantity:   quantity,
+		Serials:    serials,
+		Serialized: skus[0].Serialized,
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to pack item %v", err)
+		return nil, fmt.Errorf("failed to pack item %w", err)
+	}
+
+	return carton, nil
+}
+
+func (s *ShipmentService) SealCarton(ctx context.Context, tenantID string, cartonID int64, measure storage.CartonMeasure) (*models.Carton, error) {
+	logTag := "[ShipmentService][SealCarton]"
+	log.InfofWithContext(ctx, logTag+" sealing carton %d", cartonID)
+
+	carton, err := s.ShipmentRepo.SealCarton(ctx, tenantID, cartonID, measure)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to seal carton %v", err)
+		return nil, fmt.Errorf("failed to seal carton %w", err)
+	}
+
+	return carton, nil
+}
+
+func (s *ShipmentService) CloseShipment(ctx context.Context, tenantID string, id int64, trackingNumber string) (*models.Shipment, error) {
+	logTag := "[ShipmentService][CloseShipment]"
+	log.InfofWithContext(ctx, logTag+" closing shipment %d", id)
+
+	shipment, err := s.ShipmentRepo.Close(ctx, tenantID, id, trackingNumber)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to close shipment %v", err)
+		return nil, fmt.Errorf("failed to close shipment %w", err)
+	}
+
+	return shipment, nil
+}
+
+func (s *ShipmentService) CancelShipment(ctx context.Context, tenantID string, id int64) (*models.Shipment, error) {
+	logTag := "[ShipmentService][CancelShipment]"
+	log.InfofWithContext(ctx, logTag+" cancelling shipment %d", id)
+
+	shipment, err := s.ShipmentRepo.Cancel(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel shipment %v", err)
+		return nil, fmt.Errorf("failed to cancel shipment %w", err)
+	}
+
+	return shipment, nil
+}
+
+// GetManifest collects the shipments a hub closed for a carrier on one day,
+// the day runs midnight to midnight UTC
+func (s *ShipmentService) GetManifest(ctx context.Context, tenantID string, hubID int, carrier string, day time.Time) (*models.Manifest, error) {
+	logTag := "[ShipmentService][GetManifest]"
+	log.InfofWithContext(ctx, logTag+" building %s manifest for hub %d", carrier, hubID)
+
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+		return nil, fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != tenantID {
+		return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+	}
+
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	lines, err := s.ShipmentRepo.GetManifestLines(ctx, tenantID, hubID, carrier, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to get manifest lines %v", err)
+		return nil, fmt.Errorf("failed to get manifest lines %w", err)
+	}
+
+	manifest := &models.Manifest{
+		HubID:   hub.ID,
+		HubName: hub.Name,
+		Carrier: carrier,
+		Date:    from.Format(time.DateOnly),
+		Lines:   []models.ManifestLine{},
+	}
+
+	shipments := make(map[int64]bool)
+	cartons := make(map[string]bool)
+	for _, line := range lines {
+		shipments[line.ShipmentID] = true
+
+		cartonKey := fmt.Sprintf("%d/%s", line.ShipmentID, line.CartonCode)
+		if !cartons[cartonKey] {
+			cartons[cartonKey] = true
+			if line.WeightKg != nil {
+				manifest.WeightKg += *line.WeightKg
+			}
+		}
+
+		manifest.Units += line.Quantity
+		manifest.Lines = append(manifest.Lines, line)
+	}
+	manifest.Shipments = len(shipments)
+	manifest.Cartons = len(cartons)
+
+	return manifest, nil
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/omniful/go_commons/validator"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/datatypes"
+)
+
+// skuImportRow is one csv row, it carries the same rules as the create sku
+// request so a file accepts exactly what the api does
+type skuImportRow struct {
+	SKUCode    string         `validate:"required,min=1,max=50"`
+	Name       string         `validate:"required,min=2,max=200"`
+	Metadata   datatypes.JSON `validate:"-"`
+	Serialized bool
+	HubID      *int  `validate:"omitempty,min=1"`
+	Quantity   int64 `validate:"min=0"`
+}
+
+// skuImportRequired are the columns every sku import has to carry, the rest
+// of serialized, metadata, hub_id and quantity are optional
+var skuImportRequired = []string{"sku_code", "name"}
+
+type SKUImportService struct {
+	ImportRepo   *storage.ImportRepo
+	HubRepo      *storage.HubRepo
+	MaxRows      int
+	LeaseTimeout time.Duration
+}
+
+func NewSKUImportService(importRepo *storage.ImportRepo, hubRepo *storage.HubRepo, maxRows int, leaseTimeout time.Duration) *SKUImportService {
+	if maxRows <= 0 {
+		maxRows = 50000
+	}
+	if leaseTimeout <= 0 {
+		leaseTimeout = time.Hour
+	}
+	return &SKUImportService{
+		ImportRepo:   importRepo,
+		HubRepo:      hubRepo,
+		MaxRows:      maxRows,
+		LeaseTimeout: leaseTimeout,
+	}
+}
+
+// CreateSKUImport checks the file can be read and queues it, rows are only
+// validated once the job runs
+func (s *SKUImportService) CreateSKUImport(ctx context.Context, tenantID, sellerID, fileName string, content []byte, actor string) (*models.ImportJob, error) {
+	logTag := "[SKUImportService][CreateSKUImport]"
+	log.InfofWithContext(ctx, logTag+" queueing sku import %s for tenant %s, seller %s", fileName, tenantID, sellerID)
+
+	_, rows, err := readSKUImport(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("file has no rows to import")
+	}
+	if len(rows) > s.MaxRows {
+		return nil, fmt.Errorf("file has %d rows, at most %d are accepted", len(rows), s.MaxRows)
+	}
+
+	job := &models.ImportJob{
+		TenantID:  tenantID,
+		SellerID:  sellerID,
+		Type:      models.ImportJobTypeSKU,
+		FileName:  fileName,
+		Content:   content,
+		Actor:     actor,
+		TotalRows: len(rows),
+	}
+	if err := s.ImportRepo.Create(ctx, job); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create import job %v", err)
+		return nil, fmt.Errorf("failed to create import job %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" import job queued with ID: %d", job.ID)
+	return job, nil
+}
+
+func (s *SKUImportService) GetImport(ctx context.Context, id int64) (*models.ImportJob, error) {
+	logTag := "[SKUImportService][GetImport]"
+	log.InfofWithContext(ctx, logTag+" fetching import job %d", id)
+
+	job, err := s.ImportRepo.GetByID(ctx, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch import job %v", err)
+		return nil, fmt.Errorf("failed to fetch import job %w", err)
+	}
+
+	return job, nil
+}
+
+// GetImportErrors returns the job with the rows it rejected
+func (s *SKUImportService) GetImportErrors(ctx context.Context, id int64) (*models.ImportJob, []models.ImportJobError, error) {
+	logTag := "[SKUImportService][GetImportErrors]"
+	log.InfofWithContext(ctx, logTag+" fetching errors of import job %d", id)
+
+	job, err := s.GetImport(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rowErrors, err := s.ImportRepo.GetErrors(ctx, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch import errors %v", err)
+		return nil, nil, fmt.Errorf("failed to fetch import errors %w", err)
+	}
+
+	return job, rowErrors, nil
+}
+
+// StartWorker picks up queued imports one at a time until ctx is cancelled,
+// jobs running for longer than the lease timeout are taken over from workers
+// that died mid file
+func (s *SKUImportService) StartWorker(ctx context.Context, interval time.Duration) {
+	logTag := "[SKUImportService][StartWorker]"
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				job, err := s.ImportRepo.ClaimNext(ctx, time.Now().Add(-s.LeaseTimeout))
+				if err != nil {
+					log.ErrorfWithContext(ctx, logTag+" failed to claim import job %v", err)
+					break
+				}
+				if job == nil {
+					break
+				}
+				s.runImport(ctx, job)
+			}
+		}
+	}
+}
+
+// runImport imports every row on its own, a bad row is reported and the
+// rest of the file still goes in
+func (s *SKUImportService) runImport(ctx context.Context, job *models.ImportJob) {
+	logTag := "[SKUImportService][runImport]"
+	log.InfofWithContext(ctx, logTag+" running import job %d", job.ID)
+
+	header, rows, err := readSKUImport(job.Content)
+	if err != nil {
+		job.Status = models.ImportJobStatusFailed
+		job.Error = err.Error()
+		if err := s.ImportRepo.Finish(ctx, job, nil); err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to finish import job %v", err)
+		}
+		return
+	}
+
+	info := storage.MovementInfo{
+		Reason:    models.MovementReasonOpeningBalance,
+		Actor:     job.Actor,
+		Reference: fmt.Sprintf("import:%d", job.ID),
+	}
+
+	hubs := make(map[int]error)
+	var rowErrors []models.ImportJobError
+	job.TotalRows, job.SucceededRows, job.FailedRows, job.SkippedRows = len(rows), 0, 0, 0
+	for i, record := range rows {
+		rowNumber := i + 2
+		row, err := parseSKUImportRow(ctx, header, record)
+		if err == nil && row.HubID != nil {
+			if _, seen := hubs[*row.HubID]; !seen {
+				hubs[*row.HubID] = s.checkHub(ctx, *row.HubID, job.TenantID)
+			}
+			err = hubs[*row.HubID]
+		}
+		skipped := false
+		if err == nil {
+			skipped, err = s.ImportRepo.ImportSKU(ctx, &models.SKU{
+				TenantID:   job.TenantID,
+				SellerID:   job.SellerID,
+				SKUCode:    row.SKUCode,
+				Name:       row.Name,
+				MetaData:   row.Metadata,
+				Serialized: row.Serialized,
+			}, row.HubID, row.Quantity, info)
+		}
+
+		if err != nil {
+			job.FailedRows++
+			rowErrors = append(rowErrors, models.ImportJobError{
+				RowNumber: rowNumber,
+				SKUCode:   csvField(header, record, "sku_code"),
+				Message:   err.Error(),
+			})
+			continue
+		}
+		if skipped {
+			job.SkippedRows++
+			continue
+		}
+		job.SucceededRows++
+	}
+
+	job.Status = models.ImportJobStatusCompleted
+	if err := s.ImportRepo.Finish(ctx, job, rowErrors); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to finish import job %v", err)
+		return
+	}
+
+	log.InfofWithContext(ctx, logTag+" import job %d done, %d rows imported, %d skipped, %d rejected", job.ID, job.SucceededRows, job.SkippedRows, job.FailedRows)
+}
+
+func (s *SKUImportService) checkHub(ctx context.Context, hubID int, tenantID string) error {
+	hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+	if err != nil {
+		return fmt.Errorf("failed to get Hub ID %w", err)
+	}
+	if hub.TenantID != tenantID {
+		return fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+	}
+	return checkHubActive(hub)
+}
+
+// readSKUImport splits a csv into its header, mapped from column name to
+// position, and its data rows
+func readSKUImport(content []byte) (map[string]int, [][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	names, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header %w", err)
+	}
+
+	header := make(map[string]int, len(names))
+	for i, name := range names {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, column := range skuImportRequired {
+		if _, ok := header[column]; !ok {
+			return nil, nil, fmt.Errorf("csv header is missing the %s column", column)
+		}
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv %w", err)
+	}
+
+	return header, rows, nil
+}
+
+func parseSKUImportRow(ctx context.Context, header map[string]int, record []string) (*skuImportRow, error) {
+	row := &skuImportRow{
+		SKUCode: csvField(header, record, "sku_code"),
+		Name:    csvField(header, record, "name"),
+	}
+
+	if value := csvField(header, record, "serialized"); value != "" {
+		serialized, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("serialized must be true or false, got %s", value)
+		}
+		row.Serialized = serialized
+	}
+
+	if value := csvField(header, record, "metadata"); value != "" {
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("metadata is not valid json")
+		}
+		row.Metadata = datatypes.JSON(value)
+	}
+
+	if value := csvField(header, record, "hub_id"); value != "" {
+		hubID, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("hub_id must be a number, got %s", value)
+		}
+		row.HubID = &hubID
+	}
+
+	if value := csvField(header, record, "quantity"); value != "" {
+		quantity, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("quantity must be a whole number, got %s", value)
+		}
+		if row.HubID == nil && quantity != 0 {
+			return nil, fmt.Errorf("quantity needs a hub_id")
+		}
+		row.Quantity = quantity
+	}
+
+	if err := validator.ValidateStruct(ctx, row); err.Exists() {
+		return nil, fmt.Errorf("%s", err.ErrorMessage())
+	}
+
+	return row, nil
+}
+
+func csvField(header map[string]int, record []string, column string) string {
+	i, ok := header[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/datatypes"
+)
+
+func TestParseSKUImportRow(t *testing.T) {
+	hubID := func(id int) *int { return &id }
+	header := map[string]int{"name": 0, "sku_code": 1, "serialized": 2, "metadata": 3, "hub_id": 4, "quantity": 5}
+
+	tests := []struct {
+		name    string
+		header  map[string]int
+		record  []string
+		want    *skuImportRow
+		wantErr string
+	}{
+		{
+			name:   "columns are read by name, not position",
+			header: header,
+			record: []string{"Blue Mug", "MUG-1", "", "", "", ""},
+			want:   &skuImportRow{SKUCode: "MUG-1", Name: "Blue Mug"},
+		},
+		{
+			name:   "every optional column",
+			header: header,
+			record: []string{"Blue Mug", "MUG-1", "true", `{"color":"blue"}`, "3", "12"},
+			want: &skuImportRow{
+				SKUCode:    "MUG-1",
+				Name:       "Blue Mug",
+				Serialized: true,
+				Metadata:   datatypes.JSON(`{"color":"blue"}`),
+				HubID:      hubID(3),
+				Quantity:   12,
+			},
+		},
+		{
+			name:   "values are trimmed",
+			header: header,
+			record: []string{" Blue Mug ", " MUG-1 ", " false ", "", " 3 ", " 0 "},
+			want:   &skuImportRow{SKUCode: "MUG-1", Name: "Blue Mug", HubID: hubID(3)},
+		},
+		{
+			name:   "short rows leave the missing columns empty",
+			header: header,
+			record: []string{"Blue Mug", "MUG-1"},
+			want:   &skuImportRow{SKUCode: "MUG-1", Name: "Blue Mug"},
+		},
+		{
+			name:   "optional columns may be absent from the header",
+			header: map[string]int{"sku_code": 0, "name": 1},
+			record: []string{"MUG-1", "Blue Mug"},
+			want:   &skuImportRow{SKUCode: "MUG-1", Name: "Blue Mug"},
+		},
+		{
+			name:    "serialized must be a boolean",
+			header:  header,
+			record:  []string{"Blue Mug", "MUG-1", "maybe", "", "", ""},
+			wantErr: "serialized must be true or false",
+		},
+		{
+			name:    "metadata must be json",
+			header:  header,
+			record:  []string{"Blue Mug", "MUG-1", "", "{color:blue", "", ""},
+			wantErr: "metadata is not valid json",
+		},
+		{
+			name:    "hub_id must be a number",
+			header:  header,
+			record:  []string{"Blue Mug", "MUG-1", "", "", "north", ""},
+			wantErr: "hub_id must be a number",
+		},
+		{
+			name:    "quantity must be a whole number",
+			header:  header,
+			record:  []string{"Blue Mug", "MUG-1", "", "", "3", "1.5"},
+			wantErr: "quantity must be a whole number",
+		},
+		{
+			name:    "quantity needs a hub",
+			header:  header,
+			record:  []string{"Blue Mug", "MUG-1", "", "", "", "5"},
+			wantErr: "quantity needs a hub_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := parseSKUImportRow(context.Background(), tt.header, tt.record)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(row, tt.want) {
+				t.Errorf("row = %+v, want %+v", row, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+// SKUQuantity is a sku code and a quantity as sent by callers, serialized
+// skus also name the serial of every unit
+type SKUQuantity struct {
+	SKUCode  string
+	Quantity int64
+	Serials  []string
+}
+
+// resolveSKUCodes maps every code to its sku id, failing on unknown codes
+func resolveSKUCodes(ctx context.Context, skuRepo *storage.SKURepo, tenantID, sellerID string, codes []string) (map[string]int, error) {
+	skus, err := resolveSKUs(ctx, skuRepo, tenantID, sellerID, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int, len(skus))
+	for code, sku := range skus {
+		ids[code] = sku.ID
+	}
+	return ids, nil
+}
+
+// resolveSKUs maps every code to its sku, failing on unknown codes
+func resolveSKUs(ctx context.Context, skuRepo *storage.SKURepo, tenantID, sellerID string, codes []string) (map[string]models.SKU, error) {
+	skus, err := skuRepo.GetByCodes(ctx, tenantID, sellerID, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SKU IDs %w", err)
+	}
+
+	byCode := make(map[string]models.SKU, len(skus))
+	for _, sku := range skus {
+		byCode[sku.SKUCode] = sku
+	}
+
+	for _, code := range codes {
+		if _, ok := byCode[code]; !ok {
+			return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, code)
+		}
+	}
+
+	return byCode, nil
+}
+
+// checkSKUActive refuses new stock of an archived sku
+func checkSKUActive(sku models.SKU) error {
+	if sku.ArchivedAt != nil {
+		return fmt.Errorf("%w: %s", storage.ErrSKUArchived, sku.SKUCode)
+	}
+	return nil
+}
+
+func lineCodes(lines []SKUQuantity) []string {
+	codes := make([]string, 0, len(lines))
+	for _, line := range lines {
+		codes = append(codes, line.SKUCode)
+	}
+	return codes
+}
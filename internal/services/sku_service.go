@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/omniful/go_commons/log"
 	"github.com/singhJasvinder101/go_wms/internal/storage"
@@ -20,7 +21,7 @@ func NewSKUService(skuRepo *storage.SKURepo) *SKUService {
     }
 }
 
-func (s *SKUService) CreateSKU(ctx context.Context, tenantId, sellerId string, skuCode, name string, metadata datatypes.JSON) (*models.SKU, error) {
+func (s *SKUService) CreateSKU(ctx context.Context, tenantId, sellerId string, skuCode, name string, metadata datatypes.JSON, serialized bool) (*models.SKU, error) {
     logTag := "[SKUService][CreateSKU]"
     log.InfofWithContext(ctx, logTag+" creating SKU for tenant: %s, seller: %s", tenantId, sellerId)
 
@@ -31,6 +32,7 @@ func (s *SKUService) CreateSKU(ctx context.Context, tenantId, sellerId string, s
         SKUCode:  skuCode,
         Name:     name,
         MetaData: metadata,
+        Serialized: serialized,
     }
 
     if err := s.SKURepo.Create(ctx, sku); err != nil {
@@ -55,4 +57,88 @@ func (s *SKUService) GetSKUsByCodes(ctx context.Context, tenantID, sellerID stri
 
     log.InfofWithContext(ctx, logTag+" found %d SKUs out of %d requested", len(skus), len(skuCodes))
     return skus, nil
-}
\ No newline at end of file
+}
+
+// UpdateSKU changes the name and metadata of a sku of the seller, nil or
+// empty values leave the field as it is
+func (s *SKUService) UpdateSKU(ctx context.Context, tenantID, sellerID, skuCode string, name *string, metadata datatypes.JSON) (*models.SKU, error) {
+    logTag := "[SKUService][UpdateSKU]"
+    log.InfofWithContext(ctx, logTag+" updating SKU %s for tenant %s, seller: %s", skuCode, tenantID, sellerID)
+
+    sku, err := s.getSKU(ctx, tenantID, sellerID, skuCode)
+    if err != nil {
+        return nil, err
+    }
+
+    if name != nil {
+        sku.Name = *name
+    }
+    if len(metadata) > 0 {
+        sku.MetaData = metadata
+    }
+
+    if err := s.SKURepo.Update(ctx, sku); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to update SKU %v", err)
+        return nil, fmt.Errorf("failed to update SKU %w", err)
+    }
+
+    return sku, nil
+}
+
+// SetSKUArchived archives or restores a sku of the seller. An archived sku
+// stays readable and its remaining stock can still be sold, but it takes no
+// new stock
+func (s *SKUService) SetSKUArchived(ctx context.Context, tenantID, sellerID, skuCode string, archived bool) (*models.SKU, error) {
+    logTag := "[SKUService][SetSKUArchived]"
+    log.InfofWithContext(ctx, logTag+" setting SKU %s archived to %t", skuCode, archived)
+
+    sku, err := s.getSKU(ctx, tenantID, sellerID, skuCode)
+    if err != nil {
+        return nil, err
+    }
+
+    var archivedAt *time.Time
+    if archived {
+        if sku.ArchivedAt != nil {
+            return sku, nil
+        }
+        now := time.Now()
+        archivedAt = &now
+    }
+
+    if err := s.SKURepo.SetArchived(ctx, sku, archivedAt); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to archive SKU %v", err)
+        return nil, fmt.Errorf("failed to archive SKU %w", err)
+    }
+
+    sku.ArchivedAt = archivedAt
+    return sku, nil
+}
+
+func (s *SKUService) DeleteSKU(ctx context.Context, tenantID, sellerID, skuCode string) error {
+    logTag := "[SKUService][DeleteSKU]"
+    log.InfofWithContext(ctx, logTag+" deleting SKU %s for tenant %s, seller: %s", skuCode, tenantID, sellerID)
+
+    sku, err := s.getSKU(ctx, tenantID, sellerID, skuCode)
+    if err != nil {
+        return err
+    }
+
+    if err := s.SKURepo.Delete(ctx, sku); err != nil {
+        log.ErrorfWithContext(ctx, logTag+" failed to delete SKU %v", err)
+        return fmt.Errorf("failed to delete SKU %w", err)
+    }
+
+    return nil
+}
+
+func (s *SKUService) getSKU(ctx context.Context, tenantID, sellerID, skuCode string) (*models.SKU, error) {
+    skus, err := s.SKURepo.GetByCodes(ctx, tenantID, sellerID, []string{skuCode})
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch SKU %w", err)
+    }
+    if len(skus) == 0 {
+        return nil, fmt.Errorf("%w: %s", storage.ErrSKUNotFound, skuCode)
+    }
+    return &skus[0], nil
+}
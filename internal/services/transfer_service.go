@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/internal/storage"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+type TransferService struct {
+	TransferRepo *storage.TransferRepo
+	SKURepo      *storage.SKURepo
+	HubRepo      *storage.HubRepo
+}
+
+func NewTransferService(transferRepo *storage.TransferRepo, skuRepo *storage.SKURepo, hubRepo *storage.HubRepo) *TransferService {
+	return &TransferService{
+		TransferRepo: transferRepo,
+		SKURepo:      skuRepo,
+		HubRepo:      hubRepo,
+	}
+}
+
+func (s *TransferService) CreateTransfer(ctx context.Context, tenantID, sellerID string, sourceHubID, destinationHubID int, reference string, lines []SKUQuantity) (*models.TransferOrder, error) {
+	logTag := "[TransferService][CreateTransfer]"
+	log.InfofWithContext(ctx, logTag+" creating transfer from hub %d to hub %d", sourceHubID, destinationHubID)
+
+	if sourceHubID == destinationHubID {
+		return nil, fmt.Errorf("source and destination hub must differ")
+	}
+
+	for _, hubID := range []int{sourceHubID, destinationHubID} {
+		hub, err := s.HubRepo.GetByID(ctx, uint(hubID))
+		if err != nil {
+			log.ErrorfWithContext(ctx, logTag+" failed to get Hub ID %v", err)
+			return nil, fmt.Errorf("failed to get Hub ID %w", err)
+		}
+		if hub.TenantID != tenantID {
+			return nil, fmt.Errorf("%w: hub %d does not belong to tenant %s", storage.ErrHubNotFound, hubID, tenantID)
+		}
+		// an inactive hub may still send its stock away
+		if hubID == destinationHubID {
+			if err := checkHubActive(hub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	skuIDs, err := resolveSKUCodes(ctx, s.SKURepo, tenantID, sellerID, lineCodes(lines))
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.TransferOrder{
+		TenantID:         tenantID,
+		SellerID:         sellerID,
+		SourceHubID:      sourceHubID,
+		DestinationHubID: destinationHubID,
+		Reference:        reference,
+	}
+
+	merged := make(map[string]int)
+	for _, line := range lines {
+		if idx, ok := merged[line.SKUCode]; ok {
+			transfer.Lines[idx].Quantity += line.Quantity
+			transfer.Lines[idx].Serials = append(transfer.Lines[idx].Serials, line.Serials...)
+			continue
+		}
+		merged[line.SKUCode] = len(transfer.Lines)
+		transfer.Lines = append(transfer.Lines, models.TransferOrderLine{
+			SKUID:    skuIDs[line.SKUCode],
+			SKUCode:  line.SKUCode,
+			Quantity: line.Quantity,
+			Serials:  line.Serials,
+		})
+	}
+
+	// serialized skus are only checked against the serial table on dispatch,
+	// catch lists that can never match early
+	for _, line := range transfer.Lines {
+		if len(line.Serials) > 0 && int64(len(line.Serials)) != line.Quantity {
+			return nil, fmt.Errorf("%w: sku %s has quantity %d but %d serials", storage.ErrSerialRequired, line.SKUCode, line.Quantity, len(line.Serials))
+		}
+	}
+
+	if err := s.TransferRepo.Create(ctx, transfer); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to create transfer %v", err)
+		return nil, fmt.Errorf("failed to create transfer %w", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer created successfully with ID: %d", transfer.ID)
+	return transfer, nil
+}
+
+func (s *TransferService) GetTransfer(ctx context.Context, tenantID string, id int64) (*models.TransferOrder, error) {
+	logTag := "[TransferService][GetTransfer]"
+	log.InfofWithContext(ctx, logTag+" fetching transfer %d", id)
+
+	transfer, err := s.TransferRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch transfer %v", err)
+		return nil, fmt.Errorf("failed to fetch transfer %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) DispatchTransfer(ctx context.Context, tenantID string, id int64, info storage.MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferService][DispatchTransfer]"
+	log.InfofWithContext(ctx, logTag+" dispatching transfer %d", id)
+
+	transfer, err := s.TransferRepo.Dispatch(ctx, tenantID, id, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to dispatch transfer %v", err)
+		return nil, fmt.Errorf("failed to dispatch transfer %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) ReceiveTransfer(ctx context.Context, tenantID string, id int64, lines []SKUQuantity, info storage.MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferService][ReceiveTransfer]"
+	log.InfofWithContext(ctx, logTag+" receiving transfer %d", id)
+
+	transfer, err := s.TransferRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to fetch transfer %v", err)
+		return nil, fmt.Errorf("failed to fetch transfer %w", err)
+	}
+
+	skuIDs := make(map[string]int, len(transfer.Lines))
+	for _, line := range transfer.Lines {
+		skuIDs[line.SKUCode] = line.SKUID
+	}
+
+	received := make(map[int]int64, len(lines))
+	serials := make(map[int][]string)
+	for _, line := range lines {
+		skuID, ok := skuIDs[line.SKUCode]
+		if !ok {
+			return nil, fmt.Errorf("%w: sku %s is not on transfer %d", storage.ErrQuantityExceeded, line.SKUCode, id)
+		}
+		received[skuID] += line.Quantity
+		serials[skuID] = append(serials[skuID], line.Serials...)
+	}
+
+	transfer, err = s.TransferRepo.Receive(ctx, tenantID, id, received, serials, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to receive transfer %v", err)
+		return nil, fmt.Errorf("failed to receive transfer %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (s *TransferService) CancelTransfer(ctx context.Context, tenantID string, id int64, info storage.MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferService][CancelTransfer]"
+	log.InfofWithContext(ctx, logTag+" cancelling transfer %d", id)
+
+	transfer, err := s.TransferRepo.Cancel(ctx, tenantID, id, info)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" failed to cancel transfer %v", err)
+		return nil, fmt.Errorf("failed to cancel transfer %w", err)
+	}
+
+	return transfer, nil
+}
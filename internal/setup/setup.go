@@ -5,7 +5,7 @@ import (
 	"github.com/singhJasvinder101/go_wms/internal/handlers"
 )
 
-func SetupRoutes(server *http.Server, hubHandler *handlers.HubHandler, skuHandler *handlers.SKUHandler, inventoryHandler *handlers.InventoryHandler){
+func SetupRoutes(server *http.Server, hubHandler *handlers.HubHandler, skuHandler *handlers.SKUHandler, inventoryHandler *handlers.InventoryHandler, reservationHandler *handlers.ReservationHandler, transferHandler *handlers.TransferHandler, locationHandler *handlers.LocationHandler, serialHandler *handlers.SerialHandler, cycleCountHandler *handlers.CycleCountHandler, inboundHandler *handlers.InboundHandler, putawayHandler *handlers.PutawayHandler, allocationHandler *handlers.AllocationHandler, pickingHandler *handlers.PickingHandler, shipmentHandler *handlers.ShipmentHandler, returnHandler *handlers.ReturnHandler, idempotencyHandler *handlers.IdempotencyHandler, skuImportHandler *handlers.SKUImportHandler, replenishmentHandler *handlers.ReplenishmentHandler){
 	v1 := server.Group("/api/v1")
 	{
 		//hub routes
@@ -14,6 +14,21 @@ func SetupRoutes(server *http.Server, hubHandler *handlers.HubHandler, skuHandle
 			hubRoutes.POST("/create", hubHandler.CreateHub)
 			hubRoutes.POST("/get", hubHandler.GetHub)
 			hubRoutes.GET("/getall", hubHandler.GetAllHubs)
+			hubRoutes.POST("/nearest", hubHandler.GetNearestHubs)
+			hubRoutes.POST("/update", hubHandler.UpdateHub)
+			hubRoutes.POST("/deactivate", hubHandler.DeactivateHub)
+			hubRoutes.POST("/activate", hubHandler.ActivateHub)
+			hubRoutes.POST("/delete", hubHandler.DeleteHub)
+
+			//location routes
+			locationRoutes := hubRoutes.Group("/:id/locations")
+			{
+				locationRoutes.POST("", locationHandler.CreateLocation)
+				locationRoutes.GET("", locationHandler.GetLocations)
+				locationRoutes.GET("/:location_id", locationHandler.GetLocation)
+				locationRoutes.PATCH("/:location_id", locationHandler.UpdateLocation)
+				locationRoutes.DELETE("/:location_id", locationHandler.DeleteLocation)
+			}
 		}
 		
 
@@ -22,16 +37,150 @@ func SetupRoutes(server *http.Server, hubHandler *handlers.HubHandler, skuHandle
 		{
 			skuRoutes.POST("/create", skuHandler.CreateSKU)
 			skuRoutes.POST("/get", skuHandler.GetSKUsByCodes)
+			skuRoutes.POST("/update", skuHandler.UpdateSKU)
+			skuRoutes.POST("/archive", skuHandler.ArchiveSKU)
+			skuRoutes.POST("/restore", skuHandler.RestoreSKU)
+			skuRoutes.POST("/delete", skuHandler.DeleteSKU)
+
+			//sku import routes
+			importRoutes := skuRoutes.Group("/imports")
+			{
+				importRoutes.POST("/create", skuImportHandler.CreateSKUImport)
+				importRoutes.POST("/get", skuImportHandler.GetSKUImport)
+				importRoutes.GET("/:id/errors", skuImportHandler.DownloadSKUImportErrors)
+			}
+		}
+
+		//serial routes
+		serialRoutes := v1.Group("/serials")
+		{
+			serialRoutes.POST("/get", serialHandler.GetSerial)
 		}
 
-		//inventory routes
-		inventoryRoutes := v1.Group("/inventory")
+		//inventory routes, retries carrying an Idempotency-Key are replayed
+		inventoryRoutes := v1.Group("/inventory", idempotencyHandler.Handle)
 		{
 			inventoryRoutes.POST("/create", inventoryHandler.CreateInventory)
 			inventoryRoutes.PATCH("/upsert", inventoryHandler.UpsertInventory)
 			inventoryRoutes.POST("/get", inventoryHandler.GetInventory)
 			inventoryRoutes.POST("/getbyskus", inventoryHandler.GetInventoryBySKUs)
+			inventoryRoutes.POST("/get-as-of", inventoryHandler.GetInventoryAsOf)
+			inventoryRoutes.POST("/atp", inventoryHandler.GetNetworkATP)
 			inventoryRoutes.PATCH("/update-quantity", inventoryHandler.UpdateInventoryQuantity)
+			inventoryRoutes.POST("/adjust-batch", inventoryHandler.AdjustInventoryBatch)
+			inventoryRoutes.POST("/movements", inventoryHandler.GetMovements)
+			inventoryRoutes.GET("/export", inventoryHandler.ExportInventory)
+
+			//bin routes
+			binRoutes := inventoryRoutes.Group("/bins")
+			{
+				binRoutes.POST("/get", inventoryHandler.GetBinStock)
+				binRoutes.POST("/adjust", inventoryHandler.AdjustBinStock)
+				binRoutes.POST("/move", inventoryHandler.MoveBinStock)
+			}
+
+			//status bucket routes
+			inventoryRoutes.POST("/status/move", inventoryHandler.MoveInventoryStatus)
+
+			//lot routes
+			lotRoutes := inventoryRoutes.Group("/lots")
+			{
+				lotRoutes.POST("/receive", inventoryHandler.ReceiveLot)
+			}
+
+			//replenishment routes
+			inventoryRoutes.POST("/replenishment/report", replenishmentHandler.GetReplenishmentReport)
+
+			//reservation routes
+			reservationRoutes := inventoryRoutes.Group("/reservations")
+			{
+				reservationRoutes.POST("/reserve", reservationHandler.Reserve)
+				reservationRoutes.POST("/release", reservationHandler.Release)
+				reservationRoutes.POST("/commit", reservationHandler.Commit)
+				reservationRoutes.POST("/get", reservationHandler.GetReservations)
+			}
+		}
+
+		//cycle count routes
+		cycleCountRoutes := v1.Group("/cycle-counts")
+		{
+			cycleCountRoutes.POST("/create", cycleCountHandler.CreateCycleCount)
+			cycleCountRoutes.POST("/get", cycleCountHandler.GetCycleCount)
+			cycleCountRoutes.POST("/submit", cycleCountHandler.SubmitCounts)
+			cycleCountRoutes.POST("/approve", cycleCountHandler.ApproveCycleCount)
+			cycleCountRoutes.POST("/cancel", cycleCountHandler.CancelCycleCount)
+		}
+
+		//transfer routes
+		transferRoutes := v1.Group("/transfers")
+		{
+			transferRoutes.POST("/create", transferHandler.CreateTransfer)
+			transferRoutes.POST("/get", transferHandler.GetTransfer)
+			transferRoutes.POST("/dispatch", transferHandler.DispatchTransfer)
+			transferRoutes.POST("/receive", transferHandler.ReceiveTransfer)
+			transferRoutes.POST("/cancel", transferHandler.CancelTransfer)
+		}
+
+		//inbound routes
+		inboundRoutes := v1.Group("/inbound")
+		{
+			inboundRoutes.POST("/create", inboundHandler.CreateInbound)
+			inboundRoutes.POST("/get", inboundHandler.GetInbound)
+			inboundRoutes.POST("/receive", inboundHandler.ReceiveInbound)
+			inboundRoutes.POST("/close", inboundHandler.CloseInbound)
+			inboundRoutes.POST("/cancel", inboundHandler.CancelInbound)
+		}
+
+		//putaway routes
+		putawayRoutes := v1.Group("/putaway")
+		{
+			putawayRoutes.POST("/suggest", putawayHandler.SuggestPutaway)
+			putawayRoutes.POST("/confirm", putawayHandler.ConfirmPutaway)
+		}
+
+		//allocation routes
+		allocationRoutes := v1.Group("/allocations")
+		{
+			allocationRoutes.POST("/allocate", allocationHandler.AllocateOrder)
+			allocationRoutes.POST("/get", allocationHandler.GetAllocation)
+		}
+
+		//picking routes
+		waveRoutes := v1.Group("/waves")
+		{
+			waveRoutes.POST("/create", pickingHandler.CreateWave)
+			waveRoutes.POST("/get", pickingHandler.GetWave)
+			waveRoutes.POST("/cancel", pickingHandler.CancelWave)
+			waveRoutes.POST("/tasks/confirm", pickingHandler.ConfirmPick)
+		}
+
+		//shipment routes
+		shipmentRoutes := v1.Group("/shipments")
+		{
+			shipmentRoutes.POST("/create", shipmentHandler.CreateShipment)
+			shipmentRoutes.POST("/get", shipmentHandler.GetShipment)
+			shipmentRoutes.POST("/close", shipmentHandler.CloseShipment)
+			shipmentRoutes.POST("/cancel", shipmentHandler.CancelShipment)
+			shipmentRoutes.GET("/manifest", shipmentHandler.GetManifest)
+
+			//carton routes
+			cartonRoutes := shipmentRoutes.Group("/cartons")
+			{
+				cartonRoutes.POST("/add", shipmentHandler.AddCarton)
+				cartonRoutes.POST("/pack", shipmentHandler.PackItem)
+				cartonRoutes.POST("/seal", shipmentHandler.SealCarton)
+			}
+		}
+
+		//return routes
+		returnRoutes := v1.Group("/returns")
+		{
+			returnRoutes.POST("/create", returnHandler.CreateReturn)
+			returnRoutes.POST("/get", returnHandler.GetReturn)
+			returnRoutes.POST("/receive", returnHandler.ReceiveReturn)
+			returnRoutes.POST("/dispose", returnHandler.DisposeReturn)
+			returnRoutes.POST("/complete", returnHandler.CompleteReturn)
+			returnRoutes.POST("/cancel", returnHandler.CancelReturn)
 		}
 	}
 }
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+)
+
+type AllocationRepo struct {
+	DB *Postgres
+}
+
+func NewAllocationRepo(db *Postgres) *AllocationRepo {
+	return &AllocationRepo{
+		DB: db,
+	}
+}
+
+// Create stores the allocation and holds the units of every line that has a
+// hub until expiresAt. Availability may have moved since the plan was made,
+// if a hub can no longer cover its line nothing is stored
+func (r *AllocationRepo) Create(ctx context.Context, allocation *models.OrderAllocation, expiresAt time.Time, info MovementInfo) error {
+	logTag := "[AllocationRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating order allocation in db", "order_ref", allocation.OrderRef)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.OrderAllocation{}).
+			Where("tenant_id = ? AND seller_id = ? AND order_ref = ?", allocation.TenantID, allocation.SellerID, allocation.OrderRef).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("error when checking existing allocations %v", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("%w: %s", ErrAllocationExists, allocation.OrderRef)
+		}
+
+		for i := range allocation.Lines {
+			line := &allocation.Lines[i]
+			if line.HubID == nil {
+				continue
+			}
+
+			reservation := &models.InventoryReservation{
+				TenantID:  allocation.TenantID,
+				SellerID:  allocation.SellerID,
+				HubID:     *line.HubID,
+				SKUID:     line.SKUID,
+				OrderRef:  allocation.OrderRef,
+				Quantity:  line.Quantity,
+				ExpiresAt: expiresAt,
+			}
+			if err := reserveStock(tx, reservation, info); err != nil {
+				return fmt.Errorf("sku %s at hub %d: %w", line.SKUCode, *line.HubID, err)
+			}
+			line.ReservationID = &reservation.ID
+		}
+
+		if err := tx.Create(allocation).Error; err != nil {
+			return fmt.Errorf("error when creating order allocation in db %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating order allocation %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" order allocation created successfully", "id", allocation.ID)
+	return nil
+}
+
+func (r *AllocationRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.OrderAllocation, error) {
+	logTag := "[AllocationRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting order allocation by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var allocation models.OrderAllocation
+	if err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAllocationNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting order allocation %v", err)
+		return nil, fmt.Errorf("error when getting order allocation %v", err)
+	}
+
+	return &allocation, nil
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdjustBin changes the stock of a sku in one bin, the hub level total moves
+// by the same delta in the same transaction
+func (r *InventoryRepo) AdjustBin(ctx context.Context, tenantID, sellerID string, hubID, skuID, locationID int, delta int64, info MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryRepo][AdjustBin]"
+	log.InfofWithContext(ctx, logTag+" adjusting bin stock", "hub_id", hubID, "sku_id", skuID, "location_id", locationID, "delta", delta)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := checkBinLocation(tx, hubID, locationID); err != nil {
+			return err
+		}
+
+		var err error
+		_, movement, err = applyStockDelta(tx, stockChange{
+			TenantID:        tenantID,
+			SellerID:        sellerID,
+			HubID:           hubID,
+			SKUID:           skuID,
+			Delta:           delta,
+			CreateIfMissing: true,
+			LocationID:      &locationID,
+			// taking stock out of a bin must not take units promised to orders
+			RespectReservations: delta < 0,
+		}, info)
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when adjusting bin stock %v", err)
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+// MoveBetweenBins relocates units of a sku inside a hub without changing the
+// hub total, a nil from or to location stands for unassigned stock
+func (r *InventoryRepo) MoveBetweenBins(ctx context.Context, hubID, skuID int, fromLocationID, toLocationID *int, quantity int64, info MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryRepo][MoveBetweenBins]"
+	log.InfofWithContext(ctx, logTag+" moving bin stock", "hub_id", hubID, "sku_id", skuID, "from", fromLocationID, "to", toLocationID, "quantity", quantity)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movement, err = moveBetweenBins(tx, hubID, skuID, fromLocationID, toLocationID, quantity, info)
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when moving bin stock %v", err)
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (r *InventoryRepo) GetBinStock(ctx context.Context, hubID int, sellerID string, skuCodes []string, locationID int) ([]models.BinStock, error) {
+	logTag := "[InventoryRepo][GetBinStock]"
+	log.InfofWithContext(ctx, logTag+" getting bin stock", "hub_id", hubID, "seller_id", sellerID, "location_id", locationID)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Table("bin_inventory AS b").
+		Select("s.sku_code AS sku, b.location_id, l.code AS location_code, b.quantity").
+		Joins("JOIN skus AS s ON s.id = b.sku_id").
+		Joins("JOIN hub_locations AS l ON l.id = b.location_id").
+		Where("b.hub_id = ? AND b.seller_id = ? AND b.quantity > 0", hubID, sellerID)
+
+	if len(skuCodes) > 0 {
+		query = query.Where("s.sku_code IN ?", skuCodes)
+	}
+	if locationID > 0 {
+		query = query.Where("b.location_id = ?", locationID)
+	}
+
+	var stock []models.BinStock
+	if err := query.Order("l.code, s.sku_code").Scan(&stock).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting bin stock %v", err)
+		return nil, fmt.Errorf("error when getting bin stock %v", err)
+	}
+
+	return stock, nil
+}
+
+// GetBinOccupancy sums the units in every stocked bin of a hub across all
+// skus and sellers, bin capacity is shared by whatever sits in it
+func (r *InventoryRepo) GetBinOccupancy(ctx context.Context, hubID int) (map[int]int64, error) {
+	logTag := "[InventoryRepo][GetBinOccupancy]"
+	log.InfofWithContext(ctx, logTag+" getting bin occupancy", "hub_id", hubID)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var rows []struct {
+		LocationID int
+		Quantity   int64
+	}
+	if err := db.Model(&models.BinInventory{}).
+		Select("location_id, SUM(quantity) AS quantity").
+		Where("hub_id = ? AND quantity > 0", hubID).
+		Group("location_id").
+		Scan(&rows).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting bin occupancy %v", err)
+		return nil, fmt.Errorf("error when getting bin occupancy %v", err)
+	}
+
+	occupancy := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		occupancy[row.LocationID] = row.Quantity
+	}
+	return occupancy, nil
+}
+
+// PutawayToBin moves unassigned units of a sku into a bin, adjustBin keeps
+// it within the capacity of the bin
+func (r *InventoryRepo) PutawayToBin(ctx context.Context, hubID, skuID, locationID int, quantity int64, info MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryRepo][PutawayToBin]"
+	log.InfofWithContext(ctx, logTag+" putting stock away", "hub_id", hubID, "sku_id", skuID, "location_id", locationID, "quantity", quantity)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		movement, err = moveBetweenBins(tx, hubID, skuID, nil, &locationID, quantity, info)
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when putting stock away %v", err)
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func moveBetweenBins(tx *gorm.DB, hubID, skuID int, fromLocationID, toLocationID *int, quantity int64, info MovementInfo) (*models.InventoryMovement, error) {
+	var inventory models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ?", skuID, hubID).
+		First(&inventory).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w for hub_id=%d, sku_id=%d", ErrInventoryNotFound, hubID, skuID)
+		}
+		return nil, fmt.Errorf("error when locking inventory row %v", err)
+	}
+
+	for _, locationID := range []*int{fromLocationID, toLocationID} {
+		if locationID == nil {
+			continue
+		}
+		if err := checkBinLocation(tx, hubID, *locationID); err != nil {
+			return nil, err
+		}
+	}
+
+	if fromLocationID == nil {
+		binned, err := binnedQuantity(tx, skuID, hubID)
+		if err != nil {
+			return nil, err
+		}
+		if inventory.Quantity-binned < quantity {
+			return nil, fmt.Errorf("%w: unassigned %d, requested %d", ErrInsufficientStock, inventory.Quantity-binned, quantity)
+		}
+	} else if err := adjustBin(tx, &inventory, *fromLocationID, -quantity); err != nil {
+		return nil, err
+	}
+
+	if toLocationID != nil {
+		if err := adjustBin(tx, &inventory, *toLocationID, quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	info.Reason = models.MovementReasonBinMove
+	if info.Reference == "" {
+		info.Reference = fmt.Sprintf("bin:%s->%s", binLabel(fromLocationID), binLabel(toLocationID))
+	}
+	return recordMovement(tx, &inventory, inventory.Quantity, toLocationID, info)
+}
+
+// adjustBin applies delta to the bin row of the inventory row, creating it on
+// first use. Every path that adds stock to a bin comes through here, so this
+// is where bin capacity is enforced
+func adjustBin(tx *gorm.DB, inventory *models.Inventory, locationID int, delta int64) error {
+	if delta > 0 {
+		if err := checkBinCapacity(tx, locationID, delta); err != nil {
+			return err
+		}
+	}
+
+	bin := models.BinInventory{
+		TenantID:   inventory.TenantID,
+		SellerID:   inventory.SellerID,
+		HubID:      inventory.HubID,
+		SKUID:      inventory.SKUID,
+		LocationID: locationID,
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND location_id = ?", inventory.SKUID, locationID).
+		FirstOrCreate(&bin).Error; err != nil {
+		return fmt.Errorf("error when locking bin row %v", err)
+	}
+
+	if bin.Quantity+delta < 0 {
+		return fmt.Errorf("%w: bin %d holds %d, change %d", ErrInsufficientStock, locationID, bin.Quantity, delta)
+	}
+
+	if err := tx.Model(&bin).Update("quantity", bin.Quantity+delta).Error; err != nil {
+		return fmt.Errorf("error when updating bin row %v", err)
+	}
+
+	return nil
+}
+
+// checkBinCapacity locks the location so concurrent additions cannot overfill
+// it together, capacity is shared by every sku and seller in the bin
+func checkBinCapacity(tx *gorm.DB, locationID int, quantity int64) error {
+	var location models.HubLocation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", locationID).
+		First(&location).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: id %d", ErrLocationNotFound, locationID)
+		}
+		return fmt.Errorf("error when locking location %v", err)
+	}
+
+	if location.Capacity == nil {
+		return nil
+	}
+
+	var occupied int64
+	if err := tx.Model(&models.BinInventory{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("location_id = ?", locationID).
+		Scan(&occupied).Error; err != nil {
+		return fmt.Errorf("error when summing bin stock %v", err)
+	}
+	if occupied+quantity > *location.Capacity {
+		return fmt.Errorf("%w: bin %s has room for %d, adding %d", ErrQuantityExceeded, location.Code, max(*location.Capacity-occupied, 0), quantity)
+	}
+
+	return nil
+}
+
+// shrinkBinsTo takes stock out of bins when a hub level decrement left fewer
+// units than the bins account for, unassigned stock is always consumed first
+func shrinkBinsTo(tx *gorm.DB, inventory *models.Inventory) error {
+	var bins []models.BinInventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ? AND quantity > 0", inventory.SKUID, inventory.HubID).
+		Order("quantity, id").
+		Find(&bins).Error; err != nil {
+		return fmt.Errorf("error when locking bin rows %v", err)
+	}
+
+	var binned int64
+	for _, bin := range bins {
+		binned += bin.Quantity
+	}
+
+	excess := binned - inventory.Quantity
+	for i := 0; excess > 0 && i < len(bins); i++ {
+		take := min(bins[i].Quantity, excess)
+		if err := tx.Model(&bins[i]).Update("quantity", bins[i].Quantity-take).Error; err != nil {
+			return fmt.Errorf("error when updating bin row %v", err)
+		}
+		excess -= take
+	}
+
+	return nil
+}
+
+func binnedQuantity(tx *gorm.DB, skuID, hubID int) (int64, error) {
+	var binned int64
+	if err := tx.Model(&models.BinInventory{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("sku_id = ? AND hub_id = ?", skuID, hubID).
+		Scan(&binned).Error; err != nil {
+		return 0, fmt.Errorf("error when summing bin stock %v", err)
+	}
+	return binned, nil
+}
+
+// checkBinLocation makes sure stock is only ever placed in bins of the hub
+func checkBinLocation(tx *gorm.DB, hubID, locationID int) error {
+	var location models.HubLocation
+	if err := tx.Where("hub_id = ? AND id = ?", hubID, locationID).First(&location).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: id %d in hub %d", ErrLocationNotFound, locationID, hubID)
+		}
+		return fmt.Errorf("error when getting location %v", err)
+	}
+
+	if location.Type != models.LocationTypeBin {
+		return fmt.Errorf("%w: location %s is a %s, not a bin", ErrLocationNotFound, location.Code, location.Type)
+	}
+
+	return nil
+}
+
+func binLabel(locationID *int) string {
+	if locationID == nil {
+		return "unassigned"
+	}
+	return fmt.Sprint(*locationID)
+}
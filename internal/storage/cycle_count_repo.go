@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CycleCountRepo struct {
+	DB *Postgres
+}
+
+func NewCycleCountRepo(db *Postgres) *CycleCountRepo {
+	return &CycleCountRepo{
+		DB: db,
+	}
+}
+
+// Create generates the count lines and snapshots their system quantity in the
+// same transaction. With locationIDs the count is per bin, otherwise it is for
+// the hub totals, skuIDs (code to id) narrows either down to some skus
+func (r *CycleCountRepo) Create(ctx context.Context, count *models.CycleCount, skuIDs map[string]int, locationIDs []int) error {
+	logTag := "[CycleCountRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating cycle count in db", "hub_id", count.HubID, "seller_id", count.SellerID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	ids := make([]int, 0, len(skuIDs))
+	for _, id := range skuIDs {
+		ids = append(ids, id)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var lines []models.CycleCountLine
+
+		if len(locationIDs) > 0 {
+			for _, locationID := range locationIDs {
+				if err := checkBinLocation(tx, count.HubID, locationID); err != nil {
+					return err
+				}
+			}
+
+			query := tx.Table("bin_inventory AS b").
+				Select("b.sku_id, s.sku_code, b.location_id, b.quantity AS snapshot_quantity").
+				Joins("JOIN skus AS s ON s.id = b.sku_id").
+				Where("b.hub_id = ? AND b.seller_id = ? AND b.location_id IN ?", count.HubID, count.SellerID, locationIDs)
+			if len(ids) > 0 {
+				query = query.Where("b.sku_id IN ?", ids)
+			}
+			if err := query.Order("b.location_id, s.sku_code").Scan(&lines).Error; err != nil {
+				return fmt.Errorf("error when snapshotting bin stock %v", err)
+			}
+		} else {
+			query := tx.Table("inventory AS i").
+				Select("i.sku_id, s.sku_code, i.quantity AS snapshot_quantity").
+				Joins("JOIN skus AS s ON s.id = i.sku_id").
+				Where("i.hub_id = ? AND i.seller_id = ?", count.HubID, count.SellerID)
+			if len(ids) > 0 {
+				query = query.Where("i.sku_id IN ?", ids)
+			}
+			if err := query.Order("s.sku_code").Scan(&lines).Error; err != nil {
+				return fmt.Errorf("error when snapshotting inventory %v", err)
+			}
+
+			// requested skus the hub has never stocked are counted against zero
+			found := make(map[int]bool, len(lines))
+			for _, line := range lines {
+				found[line.SKUID] = true
+			}
+			for code, id := range skuIDs {
+				if !found[id] {
+					lines = append(lines, models.CycleCountLine{SKUID: id, SKUCode: code})
+				}
+			}
+		}
+
+		if len(lines) == 0 {
+			return fmt.Errorf("%w: nothing to count at hub %d", ErrInventoryNotFound, count.HubID)
+		}
+
+		count.Status = models.CycleCountStatusOpen
+		count.Lines = lines
+		if err := tx.Create(count).Error; err != nil {
+			return fmt.Errorf("error when creating cycle count in db %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating cycle count %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" cycle count created successfully", "id", count.ID)
+	return nil
+}
+
+func (r *CycleCountRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.CycleCount, error) {
+	logTag := "[CycleCountRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting cycle count by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var count models.CycleCount
+	if err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&count).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCycleCountNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting cycle count %v", err)
+		return nil, fmt.Errorf("error when getting cycle count %v", err)
+	}
+
+	return &count, nil
+}
+
+// SubmitCounts records counted quantities per line id. A line whose variance
+// is above thresholdPct is flagged for a recount, the recount is then taken
+// as is. The count waits for approval once every line is settled
+func (r *CycleCountRepo) SubmitCounts(ctx context.Context, tenantID string, id int64, counted map[int64]int64, countedBy string, thresholdPct int) (*models.CycleCount, error) {
+	logTag := "[CycleCountRepo][SubmitCounts]"
+	log.InfofWithContext(ctx, logTag+" submitting cycle count quantities", "id", id, "lines", len(counted))
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var count *models.CycleCount
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = lockCycleCount(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if count.Status != models.CycleCountStatusOpen && count.Status != models.CycleCountStatusRecountRequired {
+			return fmt.Errorf("%w: cannot submit counts to a %s cycle count", ErrInvalidTransition, count.Status)
+		}
+
+		now := time.Now()
+		for i := range count.Lines {
+			line := &count.Lines[i]
+			quantity, ok := counted[line.ID]
+			if !ok {
+				continue
+			}
+			delete(counted, line.ID)
+
+			switch {
+			case line.RecountRequired:
+				line.RecountRequired = false
+				line.Recounts++
+			case line.CountedQuantity != nil:
+				return fmt.Errorf("%w: line %d is already counted", ErrInvalidTransition, line.ID)
+			}
+
+			variance := quantity - line.SnapshotQuantity
+			line.CountedQuantity = &quantity
+			line.Variance = &variance
+			line.CountedBy = countedBy
+			line.CountedAt = &now
+			if line.Recounts == 0 && line.VarianceAbove(thresholdPct) {
+				line.RecountRequired = true
+			}
+
+			if err := tx.Save(line).Error; err != nil {
+				return fmt.Errorf("error when updating cycle count line %v", err)
+			}
+		}
+
+		if len(counted) > 0 {
+			return fmt.Errorf("counted lines that are not on cycle count %d", id)
+		}
+
+		count.Status = models.CycleCountStatusPendingApproval
+		for _, line := range count.Lines {
+			if line.CountedQuantity == nil {
+				count.Status = models.CycleCountStatusOpen
+				break
+			}
+			if line.RecountRequired {
+				count.Status = models.CycleCountStatusRecountRequired
+			}
+		}
+
+		return tx.Model(count).Update("status", count.Status).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when submitting cycle count %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" cycle count quantities submitted", "id", id, "status", count.Status)
+	return count, nil
+}
+
+// Approve posts the variance of every line through the normal stock path, bin
+// lines adjust their bin along with the hub total
+func (r *CycleCountRepo) Approve(ctx context.Context, tenantID string, id int64, approvedBy string, info MovementInfo) (*models.CycleCount, error) {
+	logTag := "[CycleCountRepo][Approve]"
+	log.InfofWithContext(ctx, logTag+" approving cycle count", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var count *models.CycleCount
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = lockCycleCount(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if count.Status != models.CycleCountStatusPendingApproval {
+			return fmt.Errorf("%w: cannot approve a %s cycle count", ErrInvalidTransition, count.Status)
+		}
+
+		info.Reason = models.MovementReasonCycleCount
+		info = withReference(info, fmt.Sprintf("cycle_count:%d", count.ID))
+		for _, line := range count.Lines {
+			if line.Variance == nil || *line.Variance == 0 {
+				continue
+			}
+
+			if _, _, err := applyStockDelta(tx, stockChange{
+				TenantID:        count.TenantID,
+				SellerID:        count.SellerID,
+				HubID:           count.HubID,
+				SKUID:           line.SKUID,
+				Delta:           *line.Variance,
+				CreateIfMissing: true,
+				LocationID:      line.LocationID,
+			}, info); err != nil {
+				return fmt.Errorf("sku %s: %w", line.SKUCode, err)
+			}
+		}
+
+		now := time.Now()
+		count.Status = models.CycleCountStatusApproved
+		count.ApprovedBy = approvedBy
+		count.ApprovedAt = &now
+		return tx.Model(count).Updates(map[string]interface{}{
+			"status":      count.Status,
+			"approved_by": count.ApprovedBy,
+			"approved_at": count.ApprovedAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when approving cycle count %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" cycle count approved successfully", "id", id)
+	return count, nil
+}
+
+func (r *CycleCountRepo) Cancel(ctx context.Context, tenantID string, id int64) (*models.CycleCount, error) {
+	logTag := "[CycleCountRepo][Cancel]"
+	log.InfofWithContext(ctx, logTag+" cancelling cycle count", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var count *models.CycleCount
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = lockCycleCount(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		switch count.Status {
+		case models.CycleCountStatusApproved, models.CycleCountStatusCancelled:
+			return fmt.Errorf("%w: cannot cancel a %s cycle count", ErrInvalidTransition, count.Status)
+		}
+
+		count.Status = models.CycleCountStatusCancelled
+		return tx.Model(count).Update("status", count.Status).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when cancelling cycle count %v", err)
+		return nil, err
+	}
+
+	return count, nil
+}
+
+func lockCycleCount(tx *gorm.DB, tenantID string, id int64) (*models.CycleCount, error) {
+	var count models.CycleCount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&count).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCycleCountNotFound
+		}
+		return nil, fmt.Errorf("error when locking cycle count %v", err)
+	}
+
+	if err := tx.Where("cycle_count_id = ?", id).Order("id").Find(&count.Lines).Error; err != nil {
+		return nil, fmt.Errorf("error when getting cycle count lines %v", err)
+	}
+
+	return &count, nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import "errors"
+
+var (
+	ErrHubNotFound         = errors.New("no hub found")
+	ErrInventoryNotFound   = errors.New("inventory not found")
+	ErrInsufficientStock   = errors.New("insufficient available stock")
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationExists   = errors.New("reservation already held for this order")
+	ErrReservationNotHeld  = errors.New("reservation is not held")
+	ErrReservationExpired  = errors.New("reservation has expired")
+	ErrTransferNotFound    = errors.New("transfer order not found")
+	ErrInvalidTransition   = errors.New("invalid status transition")
+	ErrQuantityExceeded    = errors.New("quantity exceeds what is outstanding")
+	ErrLocationNotFound    = errors.New("location not found")
+	ErrLocationInUse       = errors.New("location still has child locations or stock")
+	ErrLotExpired          = errors.New("lot has expired")
+	ErrSerialNotFound      = errors.New("serial not found")
+	ErrSerialRequired      = errors.New("serial numbers do not match the units moved")
+	ErrSerialConflict      = errors.New("serial is not where the change expects it")
+	ErrCycleCountNotFound  = errors.New("cycle count not found")
+	ErrInboundNotFound     = errors.New("inbound order not found")
+	ErrInboundExists       = errors.New("inbound order with this reference already exists")
+	ErrAllocationNotFound  = errors.New("order allocation not found")
+	ErrAllocationExists    = errors.New("order is already allocated")
+	ErrWaveNotFound        = errors.New("pick wave not found")
+	ErrPickTaskNotFound    = errors.New("pick task not found")
+	ErrShipmentNotFound    = errors.New("shipment not found")
+	ErrCartonNotFound      = errors.New("carton not found")
+	ErrReturnNotFound      = errors.New("return authorization not found")
+	ErrReturnExists        = errors.New("return authorization with this reference already exists")
+	ErrIdempotencyKeyInUse = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyMismatch = errors.New("idempotency key was already used for a different request")
+	ErrVersionConflict     = errors.New("inventory row was changed since it was read")
+	ErrImportJobNotFound   = errors.New("import job not found")
+	ErrHubInactive         = errors.New("hub is inactive")
+	ErrHubInUse            = errors.New("hub still holds stock or open work")
+	ErrSKUNotFound         = errors.New("sku not found")
+	ErrSKUArchived         = errors.New("sku is archived")
+	ErrSKUInUse            = errors.New("sku still has stock or is on open orders")
+	ErrSKUExists           = errors.New("sku already exists with different details")
+)
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+)
+
+func (r *HubRepo) CreateLocation(ctx context.Context, location *models.HubLocation) error {
+	logTag := "[HubRepo][CreateLocation]"
+	log.InfofWithContext(ctx, logTag+" creating hub location in db", "location", location)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := db.Create(location).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating hub location in db %v", err)
+		return fmt.Errorf("error when creating hub location in db %v", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" hub location created successfully", "id", location.ID)
+	return nil
+}
+
+func (r *HubRepo) GetLocation(ctx context.Context, hubID, id int) (*models.HubLocation, error) {
+	logTag := "[HubRepo][GetLocation]"
+	log.InfofWithContext(ctx, logTag+" getting hub location by id", "hub_id", hubID, "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var location models.HubLocation
+	if err := db.Where("hub_id = ? AND id = ?", hubID, id).First(&location).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: id %d in hub %d", ErrLocationNotFound, id, hubID)
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting hub location %v", err)
+		return nil, fmt.Errorf("error when getting hub location %v", err)
+	}
+
+	return &location, nil
+}
+
+// GetLocations lists the locations of a hub, optionally only those of one type
+func (r *HubRepo) GetLocations(ctx context.Context, hubID int, locationType string) ([]models.HubLocation, error) {
+	logTag := "[HubRepo][GetLocations]"
+	log.InfofWithContext(ctx, logTag+" getting hub locations", "hub_id", hubID, "type", locationType)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Where("hub_id = ?", hubID)
+	if locationType != "" {
+		query = query.Where("type = ?", locationType)
+	}
+
+	var locations []models.HubLocation
+	if err := query.Order("code").Find(&locations).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting hub locations %v", err)
+		return nil, fmt.Errorf("error when getting hub locations %v", err)
+	}
+
+	return locations, nil
+}
+
+func (r *HubRepo) UpdateLocation(ctx context.Context, location *models.HubLocation) error {
+	logTag := "[HubRepo][UpdateLocation]"
+	log.InfofWithContext(ctx, logTag+" updating hub location in db", "location", location)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := db.Model(location).Select("code", "name", "capacity", "attributes", "walk_sequence").Updates(location).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when updating hub location %v", err)
+		return fmt.Errorf("error when updating hub location %v", err)
+	}
+
+	return nil
+}
+
+// locationReferenceTables keep pointing at a bin after the work on it is
+// done, a location named on any of them cannot be deleted
+var locationReferenceTables = []string{
+	"pick_tasks",
+	"cycle_count_lines",
+}
+
+// DeleteLocation removes a location that has no children, holds no stock and
+// is not named on any pick task or cycle count line
+func (r *HubRepo) DeleteLocation(ctx context.Context, hubID, id int) error {
+	logTag := "[HubRepo][DeleteLocation]"
+	log.InfofWithContext(ctx, logTag+" deleting hub location", "hub_id", hubID, "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&models.HubLocation{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return fmt.Errorf("error when counting child locations %v", err)
+		}
+
+		var stocked int64
+		if err := tx.Model(&models.BinInventory{}).Where("location_id = ? AND quantity > 0", id).Count(&stocked).Error; err != nil {
+			return fmt.Errorf("error when counting bin stock %v", err)
+		}
+
+		if children > 0 || stocked > 0 {
+			return ErrLocationInUse
+		}
+
+		for _, table := range locationReferenceTables {
+			var referenced int64
+			if err := tx.Table(table).Where("location_id = ?", id).Count(&referenced).Error; err != nil {
+				return fmt.Errorf("error when counting %s %v", table, err)
+			}
+			if referenced > 0 {
+				return fmt.Errorf("%w: location %d is on %d rows of %s", ErrLocationInUse, id, referenced, table)
+			}
+		}
+
+		if err := tx.Where("location_id = ?", id).Delete(&models.BinInventory{}).Error; err != nil {
+			return fmt.Errorf("error when deleting empty bin rows %v", err)
+		}
+
+		result := tx.Where("hub_id = ? AND id = ?", hubID, id).Delete(&models.HubLocation{})
+		if result.Error != nil {
+			return fmt.Errorf("error when deleting hub location %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: id %d in hub %d", ErrLocationNotFound, id, hubID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when deleting hub location %v", err)
+		return err
+	}
+
+	return nil
+}
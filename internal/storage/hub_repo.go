@@ -2,11 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/omniful/go_commons/log"
 	"github.com/singhJasvinder101/go_wms/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 
@@ -44,7 +46,7 @@ func (r *HubRepo) GetByID(ctx context.Context, id uint) (*models.Hub, error) {
 	var hub models.Hub
 	if err := db.Where("id = ?", id).First(&hub).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            return nil, fmt.Errorf("no hub found with id %d", id)
+            return nil, fmt.Errorf("%w with id %d", ErrHubNotFound, id)
         }
         log.ErrorfWithContext(ctx, logTag+" error when finding hub in db", err)
         return nil, fmt.Errorf("error when fetching hub by id: %v", err)
@@ -71,4 +73,143 @@ func (r *HubRepo) GetAll(ctx context.Context) ([]models.Hub, error) {
 	return hubs, nil
 }
 
+// GetActiveByTenant returns the active hubs of the tenant
+func (r *HubRepo) GetActiveByTenant(ctx context.Context, tenantID string) ([]models.Hub, error) {
+	logTag := "[HubRepo][GetActiveByTenant]"
+	log.InfofWithContext(ctx, logTag+" geting active hubs of tenant in database ", "tenant_id", tenantID)
 
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var hubs []models.Hub
+	if err := db.Where("tenant_id = ? AND active", tenantID).Find(&hubs).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when finding hubs of tenant in db %v", err)
+		return nil, fmt.Errorf("error when fetching hubs of tenant %v", err)
+	}
+
+	return hubs, nil
+}
+
+// Update saves the descriptive fields of a hub
+func (r *HubRepo) Update(ctx context.Context, hub *models.Hub) error {
+	logTag := "[HubRepo][Update]"
+	log.InfofWithContext(ctx, logTag+" updating hub in db", "hub", hub)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := db.Model(hub).Select("name", "location", "attributes").Updates(hub).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when updating hub %v", err)
+		return fmt.Errorf("error when updating hub %v", err)
+	}
+
+	return nil
+}
+
+func (r *HubRepo) SetActive(ctx context.Context, id int, active bool) error {
+	logTag := "[HubRepo][SetActive]"
+	log.InfofWithContext(ctx, logTag+" setting hub active flag in db", "id", id, "active", active)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	result := db.Model(&models.Hub{}).Where("id = ?", id).Update("active", active)
+	if result.Error != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when setting hub active flag %v", result.Error)
+		return fmt.Errorf("error when setting hub active flag %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w with id %d", ErrHubNotFound, id)
+	}
+
+	return nil
+}
+
+// hubOrderTables hold rows that keep pointing at a hub after the work on
+// them is done, a hub on any of them cannot be deleted
+var hubOrderTables = []string{
+	"inbound_orders",
+	"order_allocation_lines",
+	"pick_waves",
+	"shipments",
+	"return_authorizations",
+	"cycle_counts",
+	"serials",
+}
+
+// Delete removes a hub that holds no stock in any condition bucket, has no
+// held reservations and appears on no order. Its empty inventory rows go
+// first, inventory no longer cascades from hubs so stock can never be wiped
+// with the hub row, only its locations go with it
+func (r *HubRepo) Delete(ctx context.Context, id int) error {
+	logTag := "[HubRepo][Delete]"
+	log.InfofWithContext(ctx, logTag+" deleting hub", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var hub models.Hub
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&hub).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w with id %d", ErrHubNotFound, id)
+			}
+			return fmt.Errorf("error when locking hub %v", err)
+		}
+
+		var stock struct {
+			SKUs  int64
+			Units int64
+		}
+		if err := tx.Table("inventory AS i").
+			Select("COUNT(*) AS skus, COALESCE(SUM("+onHandExpr+"), 0) AS units").
+			Where("i.hub_id = ? AND (i.quantity <> 0 OR i.damaged_quantity <> 0 OR i.quarantine_quantity <> 0 OR i.expired_quantity <> 0)", id).
+			Scan(&stock).Error; err != nil {
+			return fmt.Errorf("error when summing hub stock %v", err)
+		}
+		if stock.SKUs > 0 {
+			return fmt.Errorf("%w: hub %d holds %d units of %d skus", ErrHubInUse, id, stock.Units, stock.SKUs)
+		}
+
+		var transfers int64
+		if err := tx.Model(&models.TransferOrder{}).
+			Where("source_hub_id = ? OR destination_hub_id = ?", id, id).
+			Count(&transfers).Error; err != nil {
+			return fmt.Errorf("error when counting transfer orders %v", err)
+		}
+		if transfers > 0 {
+			return fmt.Errorf("%w: hub %d is on %d transfer orders", ErrHubInUse, id, transfers)
+		}
+
+		var held int64
+		if err := tx.Model(&models.InventoryReservation{}).
+			Where("hub_id = ? AND status = ?", id, models.ReservationStatusHeld).
+			Count(&held).Error; err != nil {
+			return fmt.Errorf("error when counting reservations %v", err)
+		}
+		if held > 0 {
+			return fmt.Errorf("%w: hub %d has %d held reservations", ErrHubInUse, id, held)
+		}
+
+		for _, table := range hubOrderTables {
+			var rows int64
+			if err := tx.Table(table).Where("hub_id = ?", id).Count(&rows).Error; err != nil {
+				return fmt.Errorf("error when counting %s %v", table, err)
+			}
+			if rows > 0 {
+				return fmt.Errorf("%w: hub %d is on %d rows of %s", ErrHubInUse, id, rows, table)
+			}
+		}
+
+		if err := tx.Where("hub_id = ?", id).Delete(&models.Inventory{}).Error; err != nil {
+			return fmt.Errorf("error when deleting empty inventory rows %v", err)
+		}
+
+		if err := tx.Delete(&hub).Error; err != nil {
+			return fmt.Errorf("error when deleting hub %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when deleting hub %v", err)
+		return err
+	}
+
+	return nil
+}
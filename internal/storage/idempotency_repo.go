@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyScope names one key, the same key may be used by another tenant
+// or on another route without clashing
+type IdempotencyScope struct {
+	TenantID string
+	Method   string
+	Path     string
+	Key      string
+}
+
+func (s IdempotencyScope) where(db *gorm.DB) *gorm.DB {
+	return db.Where("tenant_id = ? AND method = ? AND path = ? AND key = ?", s.TenantID, s.Method, s.Path, s.Key)
+}
+
+type IdempotencyRepo struct {
+	DB *Postgres
+}
+
+func NewIdempotencyRepo(db *Postgres) *IdempotencyRepo {
+	return &IdempotencyRepo{
+		DB: db,
+	}
+}
+
+// Claim stores the key as pending unless a live record already holds it. It
+// returns the existing record when the key was already used, nil when the
+// caller now owns the key and has to run the request. A key left pending
+// since before staleBefore belongs to a request that never finished and is
+// taken over
+func (r *IdempotencyRepo) Claim(ctx context.Context, record *models.IdempotencyKey, staleBefore time.Time) (*models.IdempotencyKey, error) {
+	logTag := "[IdempotencyRepo][Claim]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	scope := IdempotencyScope{
+		TenantID: record.TenantID,
+		Method:   record.Method,
+		Path:     record.Path,
+		Key:      record.Key,
+	}
+
+	var existing *models.IdempotencyKey
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// an expired key or an abandoned pending one is free to be used again
+		if err := scope.where(tx).
+			Where("expires_at <= ? OR (status = ? AND created_at <= ?)", time.Now(), models.IdempotencyStatusPending, staleBefore).
+			Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return fmt.Errorf("error when clearing expired idempotency key %v", err)
+		}
+
+		record.Status = models.IdempotencyStatusPending
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return fmt.Errorf("error when storing idempotency key %v", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+
+		var found models.IdempotencyKey
+		if err := scope.where(tx).First(&found).Error; err != nil {
+			return fmt.Errorf("error when getting idempotency key %v", err)
+		}
+		existing = &found
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when claiming idempotency key %v", err)
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response of the request that owns the key
+func (r *IdempotencyRepo) Complete(ctx context.Context, scope IdempotencyScope, code int, headers map[string]string, body []byte) error {
+	logTag := "[IdempotencyRepo][Complete]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	stored := datatypes.JSONMap{}
+	for name, value := range headers {
+		stored[name] = value
+	}
+
+	if err := scope.where(db.Model(&models.IdempotencyKey{})).
+		Where("status = ?", models.IdempotencyStatusPending).
+		Updates(map[string]interface{}{
+			"status":           models.IdempotencyStatusCompleted,
+			"response_code":    code,
+			"response_body":    body,
+			"response_headers": stored,
+		}).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when storing idempotent response %v", err)
+		return fmt.Errorf("error when storing idempotent response %v", err)
+	}
+
+	return nil
+}
+
+// Release drops a pending key so the request can be retried with it
+func (r *IdempotencyRepo) Release(ctx context.Context, scope IdempotencyScope) error {
+	logTag := "[IdempotencyRepo][Release]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := scope.where(db).Where("status = ?", models.IdempotencyStatusPending).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when releasing idempotency key %v", err)
+		return fmt.Errorf("error when releasing idempotency key %v", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	logTag := "[IdempotencyRepo][DeleteExpired]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	result := db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when deleting expired idempotency keys %v", result.Error)
+		return 0, fmt.Errorf("error when deleting expired idempotency keys %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportRepo struct {
+	DB *Postgres
+}
+
+func NewImportRepo(db *Postgres) *ImportRepo {
+	return &ImportRepo{
+		DB: db,
+	}
+}
+
+func (r *ImportRepo) Create(ctx context.Context, job *models.ImportJob) error {
+	logTag := "[ImportRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating import job in db", "type", job.Type, "file_name", job.FileName)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	job.Status = models.ImportJobStatusPending
+	if err := db.Create(job).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating import job %v", err)
+		return fmt.Errorf("error when creating import job %v", err)
+	}
+
+	return nil
+}
+
+func (r *ImportRepo) GetByID(ctx context.Context, id int64) (*models.ImportJob, error) {
+	logTag := "[ImportRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting import job by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var job models.ImportJob
+	if err := db.Omit("content").Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting import job %v", err)
+		return nil, fmt.Errorf("error when getting import job %v", err)
+	}
+
+	return &job, nil
+}
+
+func (r *ImportRepo) GetErrors(ctx context.Context, jobID int64) ([]models.ImportJobError, error) {
+	logTag := "[ImportRepo][GetErrors]"
+	log.InfofWithContext(ctx, logTag+" getting import job errors", "job_id", jobID)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var rowErrors []models.ImportJobError
+	if err := db.Where("job_id = ?", jobID).Order("row_number, id").Find(&rowErrors).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting import job errors %v", err)
+		return nil, fmt.Errorf("error when getting import job errors %v", err)
+	}
+
+	return rowErrors, nil
+}
+
+// ClaimNext marks the oldest pending job as running and returns it, nil when
+// there is nothing to do. A job still running since before staleBefore lost
+// its worker and is claimed again, rows it already imported come back as
+// skipped or rejected. Skipping locked rows lets several workers claim jobs
+// side by side
+func (r *ImportRepo) ClaimNext(ctx context.Context, staleBefore time.Time) (*models.ImportJob, error) {
+	logTag := "[ImportRepo][ClaimNext]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var job *models.ImportJob
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var pending models.ImportJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND started_at <= ?)",
+				models.ImportJobStatusPending, models.ImportJobStatusRunning, staleBefore).
+			Order("id").
+			First(&pending).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error when claiming import job %v", err)
+		}
+
+		now := time.Now()
+		pending.Status = models.ImportJobStatusRunning
+		pending.StartedAt = &now
+		if err := tx.Model(&pending).Updates(map[string]interface{}{
+			"status":     pending.Status,
+			"started_at": pending.StartedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("error when starting import job %v", err)
+		}
+
+		job = &pending
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when claiming import job %v", err)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Finish records the outcome of a job along with the rows it rejected
+func (r *ImportRepo) Finish(ctx context.Context, job *models.ImportJob, rowErrors []models.ImportJobError) error {
+	logTag := "[ImportRepo][Finish]"
+	log.InfofWithContext(ctx, logTag+" finishing import job", "id", job.ID, "status", job.Status)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	now := time.Now()
+	job.FinishedAt = &now
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range rowErrors {
+			rowErrors[i].JobID = job.ID
+		}
+		if len(rowErrors) > 0 {
+			if err := tx.CreateInBatches(rowErrors, 500).Error; err != nil {
+				return fmt.Errorf("error when recording import errors %v", err)
+			}
+		}
+
+		return tx.Model(job).Updates(map[string]interface{}{
+			"status":         job.Status,
+			"total_rows":     job.TotalRows,
+			"succeeded_rows": job.SucceededRows,
+			"failed_rows":    job.FailedRows,
+			"skipped_rows":   job.SkippedRows,
+			"error":          job.Error,
+			"finished_at":    job.FinishedAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when finishing import job %v", err)
+		return fmt.Errorf("error when finishing import job %w", err)
+	}
+
+	return nil
+}
+
+// ImportSKU creates the sku unless the seller already has it, then books the
+// opening stock at hubID when one is given. The hub must not hold the sku yet.
+// An existing sku has to match the row, a row that only repeats it reports
+// skipped
+func (r *ImportRepo) ImportSKU(ctx context.Context, sku *models.SKU, hubID *int, quantity int64, info MovementInfo) (bool, error) {
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	skipped := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing models.SKU
+		err := tx.Where("tenant_id = ? AND seller_id = ? AND sku_code = ?", sku.TenantID, sku.SellerID, sku.SKUCode).
+			First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(sku).Error; err != nil {
+				return fmt.Errorf("error when creating sku %v", err)
+			}
+		case err != nil:
+			return fmt.Errorf("error when getting sku %v", err)
+		default:
+			if !sameSKUDetails(&existing, sku) {
+				return fmt.Errorf("%w: %s", ErrSKUExists, sku.SKUCode)
+			}
+			*sku = existing
+			skipped = hubID == nil
+		}
+
+		if hubID == nil {
+			return nil
+		}
+		if sku.ArchivedAt != nil {
+			return fmt.Errorf("%w: %s", ErrSKUArchived, sku.SKUCode)
+		}
+
+		var stocked int64
+		if err := tx.Model(&models.Inventory{}).
+			Where("sku_id = ? AND hub_id = ?", sku.ID, *hubID).
+			Count(&stocked).Error; err != nil {
+			return fmt.Errorf("error when checking existing stock %v", err)
+		}
+		if stocked > 0 {
+			return fmt.Errorf("sku %s already has stock at hub %d", sku.SKUCode, *hubID)
+		}
+
+		inventory := &models.Inventory{
+			TenantID: sku.TenantID,
+			SellerID: sku.SellerID,
+			HubID:    *hubID,
+			SKUID:    sku.ID,
+			Quantity: quantity,
+		}
+		if err := tx.Create(inventory).Error; err != nil {
+			return fmt.Errorf("error when creating inventory %v", err)
+		}
+
+		movement, err := recordMovement(tx, inventory, 0, nil, info)
+		if err != nil {
+			return err
+		}
+		return applySerials(tx, inventory, movement, nil)
+	})
+	return skipped, err
+}
+
+// sameSKUDetails reports whether an imported row describes the sku as it is
+// stored, metadata is compared as json so key order and spacing do not count
+func sameSKUDetails(stored, row *models.SKU) bool {
+	if stored.Name != row.Name || stored.Serialized != row.Serialized {
+		return false
+	}
+
+	var a, b interface{}
+	if err := json.Unmarshal(orEmptyObject(stored.MetaData), &a); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(orEmptyObject(row.MetaData), &b); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func orEmptyObject(data []byte) []byte {
+	if len(data) == 0 {
+		return []byte("{}")
+	}
+	return data
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboundReceipt is the quantity of one sku that arrived against an inbound
+// order, serialized skus name every unit
+type InboundReceipt struct {
+	SKUID    int
+	SKUCode  string
+	Quantity int64
+	Serials  []string
+}
+
+type InboundRepo struct {
+	DB *Postgres
+}
+
+func NewInboundRepo(db *Postgres) *InboundRepo {
+	return &InboundRepo{
+		DB: db,
+	}
+}
+
+func (r *InboundRepo) Create(ctx context.Context, order *models.InboundOrder) error {
+	logTag := "[InboundRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating inbound order in db", "order", order)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.InboundOrder{}).
+			Where("tenant_id = ? AND seller_id = ? AND reference = ?", order.TenantID, order.SellerID, order.Reference).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("error when checking existing inbound orders %v", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("%w: %s", ErrInboundExists, order.Reference)
+		}
+
+		order.Status = models.InboundStatusOpen
+		if err := tx.Create(order).Error; err != nil {
+			return fmt.Errorf("error when creating inbound order in db %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating inbound order %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" inbound order created successfully", "id", order.ID)
+	return nil
+}
+
+func (r *InboundRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting inbound order by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var order models.InboundOrder
+	if err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInboundNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting inbound order %v", err)
+		return nil, fmt.Errorf("error when getting inbound order %v", err)
+	}
+
+	return &order, nil
+}
+
+// Receive books arrived units into the hub through the normal stock path.
+// Receiving more or less than expected is allowed, so is a sku that was not
+// on the order, the difference shows up in the discrepancy report on close
+func (r *InboundRepo) Receive(ctx context.Context, tenantID string, id int64, receipts []InboundReceipt, info MovementInfo) (*models.InboundOrder, error) {
+	logTag := "[InboundRepo][Receive]"
+	log.InfofWithContext(ctx, logTag+" receiving inbound order", "id", id, "lines", len(receipts))
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var order *models.InboundOrder
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = lockInbound(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != models.InboundStatusOpen && order.Status != models.InboundStatusReceiving {
+			return fmt.Errorf("%w: cannot receive against a %s inbound order", ErrInvalidTransition, order.Status)
+		}
+
+		info.Reason = models.MovementReasonInboundReceipt
+		info = withReference(info, fmt.Sprintf("inbound:%d", order.ID))
+		for _, receipt := range receipts {
+			lineInfo := info
+			lineInfo.Serials = receipt.Serials
+			if _, _, err := applyStockDelta(tx, stockChange{
+				TenantID:        order.TenantID,
+				SellerID:        order.SellerID,
+				HubID:           order.HubID,
+				SKUID:           receipt.SKUID,
+				Delta:           receipt.Quantity,
+				CreateIfMissing: true,
+			}, lineInfo); err != nil {
+				return fmt.Errorf("sku %s: %w", receipt.SKUCode, err)
+			}
+
+			line := inboundLine(order, receipt.SKUID)
+			if line == nil {
+				order.Lines = append(order.Lines, models.InboundOrderLine{
+					InboundOrderID: order.ID,
+					SKUID:          receipt.SKUID,
+					SKUCode:        receipt.SKUCode,
+				})
+				line = &order.Lines[len(order.Lines)-1]
+			}
+
+			line.ReceivedQuantity += receipt.Quantity
+			if err := tx.Save(line).Error; err != nil {
+				return fmt.Errorf("error when updating inbound line %v", err)
+			}
+		}
+
+		order.Status = models.InboundStatusReceiving
+		return tx.Model(order).Update("status", order.Status).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when receiving inbound order %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" inbound order received successfully", "id", id)
+	return order, nil
+}
+
+// Close stops an inbound order from taking further receipts, whatever was
+// not received by then is reported short
+func (r *InboundRepo) Close(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundRepo][Close]"
+	log.InfofWithContext(ctx, logTag+" closing inbound order", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.InboundStatusClosed, func(order *models.InboundOrder) error {
+		if order.Status != models.InboundStatusOpen && order.Status != models.InboundStatusReceiving {
+			return fmt.Errorf("%w: cannot close a %s inbound order", ErrInvalidTransition, order.Status)
+		}
+		return nil
+	})
+}
+
+// Cancel voids an inbound order nothing has been received against yet
+func (r *InboundRepo) Cancel(ctx context.Context, tenantID string, id int64) (*models.InboundOrder, error) {
+	logTag := "[InboundRepo][Cancel]"
+	log.InfofWithContext(ctx, logTag+" cancelling inbound order", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.InboundStatusCancelled, func(order *models.InboundOrder) error {
+		if order.Status != models.InboundStatusOpen {
+			return fmt.Errorf("%w: cannot cancel a %s inbound order, close it instead", ErrInvalidTransition, order.Status)
+		}
+		return nil
+	})
+}
+
+func (r *InboundRepo) finish(ctx context.Context, logTag, tenantID string, id int64, status string, check func(*models.InboundOrder) error) (*models.InboundOrder, error) {
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var order *models.InboundOrder
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = lockInbound(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := check(order); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		order.Status = status
+		order.ClosedAt = &now
+		return tx.Model(order).Updates(map[string]interface{}{
+			"status":    order.Status,
+			"closed_at": order.ClosedAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when finishing inbound order %v", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func lockInbound(tx *gorm.DB, tenantID string, id int64) (*models.InboundOrder, error) {
+	var order models.InboundOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInboundNotFound
+		}
+		return nil, fmt.Errorf("error when locking inbound order %v", err)
+	}
+
+	if err := tx.Where("inbound_order_id = ?", id).Order("id").Find(&order.Lines).Error; err != nil {
+		return nil, fmt.Errorf("error when getting inbound lines %v", err)
+	}
+
+	return &order, nil
+}
+
+func inboundLine(order *models.InboundOrder, skuID int) *models.InboundOrderLine {
+	for i := range order.Lines {
+		if order.Lines[i].SKUID == skuID {
+			return &order.Lines[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+)
+
+// StockAdjustment is one line of a batch adjustment
+type StockAdjustment struct {
+	TenantID string
+	SellerID string
+	HubID    int
+	SKUID    int
+	SKUCode  string
+	Delta    int64
+	Reason   string
+	Serials  []string
+}
+
+// AdjustBatch applies every adjustment in one transaction, a failing line
+// rolls back the whole batch. Rows are locked in hub and sku order so two
+// batches touching the same rows cannot deadlock, results come back in the
+// order of the adjustments
+func (r *InventoryRepo) AdjustBatch(ctx context.Context, adjustments []StockAdjustment, allowNegative bool, info MovementInfo) ([]models.AdjustmentResult, error) {
+	logTag := "[InventoryRepo][AdjustBatch]"
+	log.InfofWithContext(ctx, logTag+" applying batch adjustment", "lines", len(adjustments), "allow_negative", allowNegative)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var results []models.AdjustmentResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		results, err = applyAdjustments(adjustments, allowNegative, func(change stockChange, adjustment StockAdjustment) (*models.Inventory, *models.InventoryMovement, error) {
+			lineInfo := info
+			lineInfo.Reason = adjustment.Reason
+			lineInfo.Serials = adjustment.Serials
+			return applyStockDelta(tx, change, lineInfo)
+		})
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when applying batch adjustment %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" batch adjustment applied", "lines", len(results))
+	return results, nil
+}
+
+// applyAdjustments runs every line through apply in hub and sku order and
+// stops at the first failing line, the caller's transaction then rolls the
+// whole batch back
+func applyAdjustments(adjustments []StockAdjustment, allowNegative bool, apply func(stockChange, StockAdjustment) (*models.Inventory, *models.InventoryMovement, error)) ([]models.AdjustmentResult, error) {
+	order := make([]int, len(adjustments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := adjustments[order[a]], adjustments[order[b]]
+		if x.HubID != y.HubID {
+			return x.HubID < y.HubID
+		}
+		return x.SKUID < y.SKUID
+	})
+
+	results := make([]models.AdjustmentResult, len(adjustments))
+	for _, i := range order {
+		adjustment := adjustments[i]
+
+		inventory, movement, err := apply(stockChange{
+			TenantID:        adjustment.TenantID,
+			SellerID:        adjustment.SellerID,
+			HubID:           adjustment.HubID,
+			SKUID:           adjustment.SKUID,
+			Delta:           adjustment.Delta,
+			CreateIfMissing: true,
+			AllowNegative:   allowNegative,
+			// a decrement that may not go negative must not take units
+			// already promised to orders either
+			RespectReservations: adjustment.Delta < 0 && !allowNegative,
+		}, adjustment)
+		if err != nil {
+			return nil, fmt.Errorf("line %d, sku %s at hub %d: %w", i+1, adjustment.SKUCode, adjustment.HubID, err)
+		}
+
+		results[i] = models.AdjustmentResult{
+			Line:           i + 1,
+			HubID:          adjustment.HubID,
+			SellerID:       adjustment.SellerID,
+			SKUCode:        adjustment.SKUCode,
+			Delta:          adjustment.Delta,
+			QuantityBefore: movement.QuantityBefore,
+			QuantityAfter:  inventory.Quantity,
+			Version:        inventory.Version,
+			MovementID:     movement.ID,
+		}
+	}
+	return results, nil
+}
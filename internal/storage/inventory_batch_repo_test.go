@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+func TestApplyAdjustments(t *testing.T) {
+	type level struct{ onHand, reserved int64 }
+
+	// sku 1 has nothing held, half of sku 2 is reserved for orders
+	stock := func() map[int]level {
+		return map[int]level{
+			1: {onHand: 10},
+			2: {onHand: 10, reserved: 6},
+		}
+	}
+	line := func(skuID int, delta int64) StockAdjustment {
+		return StockAdjustment{HubID: 1, SKUID: skuID, SKUCode: "SKU", Delta: delta}
+	}
+
+	tests := []struct {
+		name          string
+		adjustments   []StockAdjustment
+		allowNegative bool
+		want          map[int]level
+		wantErr       error
+	}{
+		{
+			name:        "decrements down to the reserved units apply",
+			adjustments: []StockAdjustment{line(1, -10), line(2, -4)},
+			want:        map[int]level{1: {onHand: 0}, 2: {onHand: 6, reserved: 6}},
+		},
+		{
+			name:        "a decrement into reserved stock rolls back the whole batch",
+			adjustments: []StockAdjustment{line(1, -5), line(2, 3), line(2, -8)},
+			want:        stock(),
+			wantErr:     ErrInsufficientStock,
+		},
+		{
+			name:        "a decrement below zero rolls back the whole batch",
+			adjustments: []StockAdjustment{line(2, -2), line(1, -11)},
+			want:        stock(),
+			wantErr:     ErrInsufficientStock,
+		},
+		{
+			name:          "allow negative takes reserved units too",
+			adjustments:   []StockAdjustment{line(2, -12)},
+			allowNegative: true,
+			want:          map[int]level{1: {onHand: 10}, 2: {onHand: -2, reserved: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			committed := stock()
+			// lines work on a copy that only replaces the committed levels
+			// when the whole batch went through, like the transaction
+			staged := maps.Clone(committed)
+
+			_, err := applyAdjustments(tt.adjustments, tt.allowNegative, func(change stockChange, _ StockAdjustment) (*models.Inventory, *models.InventoryMovement, error) {
+				current := staged[change.SKUID]
+				after := current.onHand + change.Delta
+				if after < 0 && !change.AllowNegative {
+					return nil, nil, ErrInsufficientStock
+				}
+				if change.Delta < 0 && change.RespectReservations {
+					if err := checkReservedStock(after, current.reserved, change.Delta); err != nil {
+						return nil, nil, err
+					}
+				}
+				staged[change.SKUID] = level{onHand: after, reserved: current.reserved}
+				return &models.Inventory{Quantity: after}, &models.InventoryMovement{QuantityBefore: current.onHand}, nil
+			})
+			if err == nil {
+				committed = staged
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(committed, tt.want) {
+				t.Errorf("stock = %v, want %v", committed, tt.want)
+			}
+		})
+	}
+}
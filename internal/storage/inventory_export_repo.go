@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+// InventoryExportFilter narrows an export down to a hub and a seller, zero
+// values export everything of the tenant
+type InventoryExportFilter struct {
+	TenantID string
+	HubID    int
+	SellerID string
+}
+
+// StreamExport hands every inventory row matching the filter to fn in hub
+// and sku order. Rows are read off the connection one at a time while fn
+// writes them out, so the table is never held in memory as a whole
+func (r *InventoryRepo) StreamExport(ctx context.Context, filter InventoryExportFilter, fn func(*models.InventoryExportRow) error) error {
+	logTag := "[InventoryRepo][StreamExport]"
+	log.InfofWithContext(ctx, logTag+" streaming inventory export", "tenant_id", filter.TenantID, "hub_id", filter.HubID, "seller_id", filter.SellerID)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Table("inventory AS i").
+		Select("i.tenant_id, i.seller_id, i.hub_id, i.sku_id, s.sku_code, s.name AS sku_name, "+onHandExpr+" AS on_hand, i.quantity AS sellable, i.damaged_quantity AS damaged, i.quarantine_quantity AS quarantine, i.expired_quantity AS expired, COALESCE(r.reserved, 0) AS reserved, COALESCE(x.expired, 0) AS expired_lots, i.quantity - COALESCE(r.reserved, 0) - COALESCE(x.expired, 0) AS available, i.version, i.updated_at").
+		Joins("JOIN skus AS s ON s.id = i.sku_id").
+		Joins(activeReservationsJoin).
+		Joins(expiredLotsJoin).
+		Where("i.tenant_id = ?", filter.TenantID)
+
+	if filter.HubID != 0 {
+		query = query.Where("i.hub_id = ?", filter.HubID)
+	}
+	if filter.SellerID != "" {
+		query = query.Where("i.seller_id = ?", filter.SellerID)
+	}
+
+	rows, err := query.Order("i.hub_id, s.sku_code").Rows()
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when querying inventory export %v", err)
+		return fmt.Errorf("error when querying inventory export %v", err)
+	}
+	defer rows.Close()
+
+	var count int
+	for rows.Next() {
+		var row models.InventoryExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			log.ErrorfWithContext(ctx, logTag+" error when reading inventory export row %v", err)
+			return fmt.Errorf("error when reading inventory export row %v", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when streaming inventory export %v", err)
+		return fmt.Errorf("error when streaming inventory export %v", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" inventory export streamed", "rows", count)
+	return nil
+}
@@ -20,48 +20,94 @@ func NewInventoryRepo(db *Postgres) *InventoryRepo {
 	}
 }
 
-func (r *InventoryRepo) Create(ctx context.Context, inventory *models.Inventory) error {
+func (r *InventoryRepo) Create(ctx context.Context, inventory *models.Inventory, info MovementInfo) (*models.InventoryMovement, error) {
 	logTag := "[SKURepo][Create]"
 	log.InfofWithContext(ctx, logTag+" creating inventory in db", "inventory", inventory)
 
 	db := r.DB.Cluster.GetMasterDB(ctx)
 
-	if err := db.Create(&inventory).Error; err != nil {
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(inventory).Error; err != nil {
+			return err
+		}
+
+		var err error
+		movement, err = recordMovement(tx, inventory, 0, nil, info)
+		if err != nil {
+			return err
+		}
+		return applySerials(tx, inventory, movement, info.Serials)
+	})
+	if err != nil {
 		log.ErrorfWithContext(ctx, logTag+" error when creating inventory in db", err)
-		return fmt.Errorf("error when creating inventory in db %v", err)
+		return nil, fmt.Errorf("error when creating inventory in db %w", err)
 	}
 
 	log.InfofWithContext(ctx, logTag+" creating inventory in db", inventory)
-	return nil
+	return movement, nil
 }
 
-func (r *InventoryRepo) Upsert(ctx context.Context, inventory *models.Inventory) error {
+// Upsert sets the on hand quantity of the row, the ledger records the
+// difference from whatever was there before. A non nil expectedVersion must
+// match the version of the row
+func (r *InventoryRepo) Upsert(ctx context.Context, inventory *models.Inventory, expectedVersion *int64, info MovementInfo) (*models.InventoryMovement, error) {
 	logTag := "[SKURepo][Upsert]"
 	log.InfofWithContext(ctx, logTag+" updating inventory in db", "inventory", inventory)
 	
 	db := r.DB.Cluster.GetMasterDB(ctx)
 
-	if err := db.Clauses(clause.OnConflict{
-        Columns:   []clause.Column{{Name: "sku_id"}, {Name: "hub_id"}},
-        DoUpdates: clause.AssignmentColumns([]string{"quantity", "tenant_id", "seller_id", "updated_at"}),
-    }).Create(inventory).Error; err != nil {
-        log.ErrorfWithContext(ctx, logTag+" error when upserting inventory in db", err)
-        return fmt.Errorf("error when upserting inventory in db %v", err)
-    }
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var before int64
+		if err := tx.Model(&models.Inventory{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("quantity").
+			Where("sku_id = ? AND hub_id = ?", inventory.SKUID, inventory.HubID).
+			Scan(&before).Error; err != nil {
+			return fmt.Errorf("error when locking inventory row %v", err)
+		}
+
+		updated, mv, err := applyStockDelta(tx, stockChange{
+			TenantID:        inventory.TenantID,
+			SellerID:        inventory.SellerID,
+			HubID:           inventory.HubID,
+			SKUID:           inventory.SKUID,
+			Delta:           inventory.Quantity - before,
+			CreateIfMissing: true,
+			ExpectedVersion: expectedVersion,
+		}, info)
+		if err != nil {
+			return err
+		}
+
+		*inventory = *updated
+		movement = mv
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when upserting inventory in db", err)
+		return nil, fmt.Errorf("error when upserting inventory in db %w", err)
+	}
 
 	log.InfofWithContext(ctx, logTag+" updating inventory in db", inventory)
-	return nil
+	return movement, nil
 }
 
 
-func (r *InventoryRepo) GetByHubAndSeller(ctx context.Context, hubID int, sellerID string) ([]models.Inventory, error) {
+func (r *InventoryRepo) GetByHubAndSeller(ctx context.Context, hubID int, sellerID string) ([]models.InventoryLevel, error) {
 	logTag := "[SKURepo][GetByHubAndSeller]"
 	log.InfofWithContext(ctx, logTag+" updating sku in db", "hub_id", hubID, "seller_id", sellerID)
 	
 	db := r.DB.Cluster.GetSlaveDB(ctx)
 
-	var inventory []models.Inventory
-	if err := db.Where("hub_id = ? AND seller_id = ?", hubID, sellerID).Find(&inventory).Error; err != nil {
+	var inventory []models.InventoryLevel
+	if err := db.Table("inventory AS i").
+		Select("i.*, "+onHandExpr+" AS on_hand, COALESCE(r.reserved, 0) AS reserved, COALESCE(x.expired, 0) AS expired_lots, i.quantity - COALESCE(r.reserved, 0) - COALESCE(x.expired, 0) AS available").
+		Joins(activeReservationsJoin).
+		Joins(expiredLotsJoin).
+		Where("i.hub_id = ? AND i.seller_id = ?", hubID, sellerID).
+		Scan(&inventory).Error; err != nil {
 		if err == gorm.ErrRecordNotFound{
 			return nil, fmt.Errorf("no record found with hub_id %d and seller_id %s", hubID, sellerID)
 		}
@@ -69,27 +115,37 @@ func (r *InventoryRepo) GetByHubAndSeller(ctx context.Context, hubID int, seller
 		return nil, fmt.Errorf("error when getting inventory by hub_id and seller_id %v", err)
 	}
 
+	skuIDs := make([]int, 0, len(inventory))
+	for _, level := range inventory {
+		skuIDs = append(skuIDs, level.SKUID)
+	}
+
+	lots, err := lotsBySKU(db, hubID, skuIDs)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting lot breakdown %v", err)
+		return nil, err
+	}
+	for i := range inventory {
+		inventory[i].Lots = append([]models.InventoryLot{}, lots[inventory[i].SKUID]...)
+	}
+
 	log.InfofWithContext(ctx, logTag+" fetching inventory successfully", inventory)
 	return inventory, nil
 }
 
-func (r *InventoryRepo) GetByHubSellerSKUs(ctx context.Context, hubID int, sellerID string, skuCodes []string) ([]struct{
-	SKU string
-	Quantity int64
-}, error) {
+func (r *InventoryRepo) GetByHubSellerSKUs(ctx context.Context, hubID int, sellerID string, skuCodes []string) ([]models.SKULevel, error) {
 	logTag := "[SKURepo][GetByHubSellerSKUs]"
 	log.InfofWithContext(ctx, logTag+" updating sku in db", "hub_id", hubID, "seller_id", sellerID)
 	
 	db := r.DB.Cluster.GetSlaveDB(ctx)
 
-	var inventory []struct{
-		SKU string
-		Quantity int64
-	}
+	var inventory []models.SKULevel
 
 	query := db.Table("inventory AS i").
-		Select("s.sku_code AS sku, i.quantity").
+		Select("i.sku_id, s.sku_code AS sku, i.version, "+onHandExpr+" AS on_hand, i.quantity AS sellable, i.damaged_quantity AS damaged, i.quarantine_quantity AS quarantine, i.expired_quantity AS expired, COALESCE(r.reserved, 0) AS reserved, COALESCE(x.expired, 0) AS expired_lots, i.quantity - COALESCE(r.reserved, 0) - COALESCE(x.expired, 0) AS available").
 		Joins("JOIN skus AS s ON s.id = i.sku_id").
+		Joins(activeReservationsJoin).
+		Joins(expiredLotsJoin).
 		Where("i.hub_id = ? AND i.seller_id = ?", hubID, sellerID)
 
 	if len(skuCodes) > 0 {
@@ -104,42 +160,73 @@ func (r *InventoryRepo) GetByHubSellerSKUs(ctx context.Context, hubID int, selle
 		return nil, fmt.Errorf("error when getting inventory by hub_id and seller_id %v", err)
 	}
 
+	skuIDs := make([]int, 0, len(inventory))
+	for _, level := range inventory {
+		skuIDs = append(skuIDs, level.SKUID)
+	}
+
+	lots, err := lotsBySKU(db, hubID, skuIDs)
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting lot breakdown %v", err)
+		return nil, err
+	}
+	for i := range inventory {
+		inventory[i].Lots = append([]models.InventoryLot{}, lots[inventory[i].SKUID]...)
+	}
+
 	log.InfofWithContext(ctx, logTag+" fetching inventory successfully", inventory)
 	return inventory, nil
 }
 
-func (r *InventoryRepo) UpdateQuantity(ctx context.Context, hubID uint, sellerID string, skuID int, quantity int) error {
+func (r *InventoryRepo) UpdateQuantity(ctx context.Context, hubID uint, sellerID string, skuID int, quantity int, expectedVersion *int64, info MovementInfo) (*models.Inventory, *models.InventoryMovement, error) {
 	logTag := "[SKURepo][GetByHubAndSeller]"
 	log.InfofWithContext(ctx, logTag+" updating sku in db", "hub_id", hubID, "seller_id", sellerID, "sku_code", skuID, "quantity", quantity)
 	
 	db := r.DB.Cluster.GetMasterDB(ctx)
 
-	var count int64
-    err := db.Model(&models.Inventory{}).
-        Where("hub_id = ? AND seller_id = ? AND sku_id = ?", hubID, sellerID, skuID).
-        Count(&count).Error
-    
-    if err != nil {
-        log.ErrorfWithContext(ctx, logTag+" error checking inventory existence: %v", err)
-        return fmt.Errorf("error checking inventory existence: %w", err)
-    }
+	var inventory *models.Inventory
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		inventory, movement, err = applyStockDelta(tx, stockChange{
+			SellerID:        sellerID,
+			HubID:           int(hubID),
+			SKUID:           skuID,
+			Delta:           int64(quantity),
+			ExpectedVersion: expectedVersion,
+			// a blind decrement must not take units already promised to orders
+			RespectReservations: quantity < 0,
+		}, info)
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when updating inventory by hub_id and seller_id %v", err)
+		return nil, nil, fmt.Errorf("error when updating inventory by hub_id, seller_id, skuID, and quanity %w", err)
+	}
 
-    if count == 0 {
-        log.ErrorfWithContext(ctx, logTag+" no inventory record found for hub_id=%d, seller_id=%s, sku_id=%d", 
-            hubID, sellerID, skuID)
-        return fmt.Errorf("inventory not found for hub_id=%d, seller_id=%s, sku_id=%d", 
-            hubID, sellerID, skuID)
-    }
+	log.InfofWithContext(ctx, "inveneotry udpated successfully")
+	return inventory, movement, nil
+}
 
+// GetHubAvailability returns the available quantity of the skus at every
+// active hub of the tenant that stocks them for the seller
+func (r *InventoryRepo) GetHubAvailability(ctx context.Context, tenantID, sellerID string, skuIDs []int) ([]models.HubAvailability, error) {
+	logTag := "[InventoryRepo][GetHubAvailability]"
+	log.InfofWithContext(ctx, logTag+" getting availability across hubs", "tenant_id", tenantID, "seller_id", sellerID)
 
-	if err := db.Model(&models.Inventory{}).
-        Where("hub_id = ? AND seller_id = ? AND sku_id = ?", hubID, sellerID, skuID).
-        Update("quantity", gorm.Expr("quantity + ?", quantity)) .Error; err != nil {
-			log.ErrorfWithContext(ctx, logTag+" error when updating inventory by hub_id and seller_id", err)
-			return fmt.Errorf("error when updating inventory by hub_id, seller_id, skuID, and quanity %v", err)
-		}
+	db := r.DB.Cluster.GetSlaveDB(ctx)
 
-	log.InfofWithContext(ctx, "inveneotry udpated successfully")
-	return nil
-}
+	var availability []models.HubAvailability
+	if err := db.Table("inventory AS i").
+		Select("i.hub_id, i.sku_id, i.quantity - COALESCE(r.reserved, 0) - COALESCE(x.expired, 0) AS available").
+		Joins("JOIN hubs AS h ON h.id = i.hub_id").
+		Joins(activeReservationsJoin).
+		Joins(expiredLotsJoin).
+		Where("h.tenant_id = ? AND h.active AND i.seller_id = ? AND i.sku_id IN ?", tenantID, sellerID, skuIDs).
+		Scan(&availability).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting availability across hubs %v", err)
+		return nil, fmt.Errorf("error when getting availability across hubs %v", err)
+	}
 
+	return availability, nil
+}
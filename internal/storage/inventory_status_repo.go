@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// onHandExpr is the physical stock of an inventory row across every bucket
+const onHandExpr = "(i.quantity + i.damaged_quantity + i.quarantine_quantity + i.expired_quantity)"
+
+// MoveStatus moves units of a sku at a hub from one condition bucket to
+// another. Leaving or entering sellable goes through the normal stock path so
+// reservations, lots, bins and serials stay consistent, moves between the
+// other buckets only leave an audit row in the ledger. Every movement carries
+// the buckets and the quantity moved since the sellable delta alone does not
+// describe the change
+func (r *InventoryRepo) MoveStatus(ctx context.Context, tenantID, sellerID string, hubID, skuID int, fromStatus, toStatus string, quantity int64, info MovementInfo) (*models.Inventory, *models.InventoryMovement, error) {
+	logTag := "[InventoryRepo][MoveStatus]"
+	log.InfofWithContext(ctx, logTag+" moving inventory between buckets", "hub_id", hubID, "sku_id", skuID, "from", fromStatus, "to", toStatus, "quantity", quantity)
+
+	fromColumn, ok := models.InventoryStatusColumn[fromStatus]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown inventory status %s", fromStatus)
+	}
+	toColumn, ok := models.InventoryStatusColumn[toStatus]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown inventory status %s", toStatus)
+	}
+
+	if info.Reference == "" {
+		info.Reference = fmt.Sprintf("status:%s->%s", fromStatus, toStatus)
+	}
+	info.FromStatus = fromStatus
+	info.ToStatus = toStatus
+	info.MovedQuantity = quantity
+	switch {
+	case fromStatus == models.InventoryStatusSellable:
+		info.UnsellableDelta = quantity
+	case toStatus == models.InventoryStatusSellable:
+		info.UnsellableDelta = -quantity
+	}
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var inventory models.Inventory
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sku_id = ? AND hub_id = ? AND seller_id = ?", skuID, hubID, sellerID).
+			First(&inventory).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w for hub_id=%d, seller_id=%s, sku_id=%d", ErrInventoryNotFound, hubID, sellerID, skuID)
+			}
+			return fmt.Errorf("error when locking inventory row %v", err)
+		}
+
+		change := stockChange{
+			TenantID: tenantID,
+			SellerID: sellerID,
+			HubID:    hubID,
+			SKUID:    skuID,
+		}
+
+		var err error
+		switch {
+		case fromStatus == models.InventoryStatusSellable:
+			change.Delta = -quantity
+			change.RespectReservations = true
+			change.ConsumeExpiredLots = toStatus == models.InventoryStatusExpired
+			_, movement, err = applyStockDelta(tx, change, info)
+		case inventory.Bucket(fromStatus) < quantity:
+			return fmt.Errorf("%w: %s holds %d, requested %d", ErrInsufficientStock, fromStatus, inventory.Bucket(fromStatus), quantity)
+		case toStatus == models.InventoryStatusSellable:
+			change.Delta = quantity
+			_, movement, err = applyStockDelta(tx, change, info)
+		default:
+			movement, err = recordMovement(tx, &inventory, inventory.Quantity, nil, info)
+		}
+		if err != nil {
+			return err
+		}
+
+		// moves through sellable already bumped the version in applyStockDelta
+		updates := map[string]interface{}{}
+		if fromStatus != models.InventoryStatusSellable && toStatus != models.InventoryStatusSellable {
+			updates["version"] = gorm.Expr("version + 1")
+		}
+		if fromStatus != models.InventoryStatusSellable {
+			updates[fromColumn] = gorm.Expr(fromColumn+" - ?", quantity)
+		}
+		if toStatus != models.InventoryStatusSellable {
+			updates[toColumn] = gorm.Expr(toColumn+" + ?", quantity)
+		}
+		if err := tx.Model(&models.Inventory{}).Where("id = ?", inventory.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("error when updating inventory buckets %v", err)
+		}
+
+		return tx.Where("id = ?", inventory.ID).First(&inventory).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when moving inventory between buckets %v", err)
+		return nil, nil, err
+	}
+
+	return &inventory, movement, nil
+}
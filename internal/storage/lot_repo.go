@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// expiredLotsJoin aggregates units sitting in expired lots per (sku_id,
+// hub_id), they stay on hand but are never counted as available
+const expiredLotsJoin = `LEFT JOIN (
+		SELECT sku_id, hub_id, SUM(quantity) AS expired
+		FROM inventory_lots
+		WHERE expires_at <= now()
+		GROUP BY sku_id, hub_id
+	) AS x ON x.sku_id = i.sku_id AND x.hub_id = i.hub_id`
+
+// ReceiveLot books quantity units of one lot into the hub, the inventory row
+// and the lot row move together in the same transaction
+func (r *InventoryRepo) ReceiveLot(ctx context.Context, lot *models.InventoryLot, info MovementInfo) (*models.InventoryMovement, error) {
+	logTag := "[InventoryRepo][ReceiveLot]"
+	log.InfofWithContext(ctx, logTag+" receiving lot in db", "lot", lot)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if info.Reference == "" {
+		info.Reference = "lot:" + lot.LotNumber
+	}
+
+	var movement *models.InventoryMovement
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, movement, err = applyStockDelta(tx, stockChange{
+			TenantID:        lot.TenantID,
+			SellerID:        lot.SellerID,
+			HubID:           lot.HubID,
+			SKUID:           lot.SKUID,
+			Delta:           lot.Quantity,
+			CreateIfMissing: true,
+		}, info)
+		if err != nil {
+			return err
+		}
+
+		quantity := lot.Quantity
+		existing := *lot
+		existing.Quantity = 0
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sku_id = ? AND hub_id = ? AND lot_number = ?", lot.SKUID, lot.HubID, lot.LotNumber).
+			FirstOrCreate(&existing).Error; err != nil {
+			return fmt.Errorf("error when locking lot row %v", err)
+		}
+
+		if err := tx.Model(&existing).Update("quantity", existing.Quantity+quantity).Error; err != nil {
+			return fmt.Errorf("error when updating lot quantity %v", err)
+		}
+		existing.Quantity += quantity
+		*lot = existing
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when receiving lot %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" lot received successfully", "id", lot.ID)
+	return movement, nil
+}
+
+// lotsBySKU groups the non empty lots of the given skus at a hub by sku_id,
+// reads use it to attach a lot breakdown to each inventory row
+func lotsBySKU(db *gorm.DB, hubID int, skuIDs []int) (map[int][]models.InventoryLot, error) {
+	bySKU := make(map[int][]models.InventoryLot, len(skuIDs))
+	if len(skuIDs) == 0 {
+		return bySKU, nil
+	}
+
+	var lots []models.InventoryLot
+	if err := db.Where("hub_id = ? AND sku_id IN ? AND quantity > 0", hubID, skuIDs).
+		Order("sku_id, expires_at ASC NULLS LAST, id").
+		Find(&lots).Error; err != nil {
+		return nil, fmt.Errorf("error when getting lots %v", err)
+	}
+
+	for _, lot := range lots {
+		bySKU[lot.SKUID] = append(bySKU[lot.SKUID], lot)
+	}
+	return bySKU, nil
+}
+
+// consumeLots takes quantity units out of the lots of an inventory row first
+// expired first out, then out of unlotted stock. Expired lots are only
+// touched when expiredFirst is set, which drains them before anything else.
+// It returns the units left in expired lots so callers can keep them out of
+// what is available
+func consumeLots(tx *gorm.DB, inventory *models.Inventory, onHand, quantity int64, expiredFirst bool) (int64, error) {
+	var lots []models.InventoryLot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ? AND quantity > 0", inventory.SKUID, inventory.HubID).
+		Order("expires_at ASC NULLS LAST, id").
+		Find(&lots).Error; err != nil {
+		return 0, fmt.Errorf("error when locking lot rows %v", err)
+	}
+
+	if len(lots) == 0 {
+		return 0, nil
+	}
+
+	taken, expired, err := planLotConsumption(lots, onHand, quantity, expiredFirst, time.Now())
+	if err != nil {
+		return expired, err
+	}
+
+	for i, n := range taken {
+		if n == 0 {
+			continue
+		}
+		if err := tx.Model(&lots[i]).Update("quantity", lots[i].Quantity-n).Error; err != nil {
+			return expired, fmt.Errorf("error when updating lot quantity %v", err)
+		}
+	}
+
+	return expired, nil
+}
+
+// planLotConsumption decides how many units consumeLots takes out of each
+// lot, lots come in the order they are drained. It returns the units taken
+// per lot and the units left in expired lots afterwards
+func planLotConsumption(lots []models.InventoryLot, onHand, quantity int64, expiredFirst bool, now time.Time) ([]int64, int64, error) {
+	var expired int64
+	for _, lot := range lots {
+		if lot.Expired(now) {
+			expired += lot.Quantity
+		}
+	}
+
+	if !expiredFirst && onHand-expired < quantity {
+		return nil, expired, fmt.Errorf("%w: %d of %d on hand are in expired lots, change -%d", ErrInsufficientStock, expired, onHand, quantity)
+	}
+
+	taken := make([]int64, len(lots))
+	remaining := quantity
+	take := func(i int) {
+		n := min(lots[i].Quantity-taken[i], remaining)
+		if lots[i].Expired(now) {
+			expired -= n
+		}
+		taken[i] += n
+		remaining -= n
+	}
+
+	if expiredFirst {
+		for i := 0; remaining > 0 && i < len(lots); i++ {
+			if lots[i].Expired(now) {
+				take(i)
+			}
+		}
+	}
+
+	for i := 0; remaining > 0 && i < len(lots); i++ {
+		if lots[i].Expired(now) || lots[i].Quantity == taken[i] {
+			continue
+		}
+		take(i)
+	}
+
+	return taken, expired, nil
+}
+
+func expiredLotQuantity(tx *gorm.DB, skuID, hubID int) (int64, error) {
+	var expired int64
+	if err := tx.Model(&models.InventoryLot{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("sku_id = ? AND hub_id = ? AND expires_at <= now()", skuID, hubID).
+		Scan(&expired).Error; err != nil {
+		return 0, fmt.Errorf("error when summing expired lots %v", err)
+	}
+	return expired, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+func TestPlanLotConsumption(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		ts := now.AddDate(0, 0, days)
+		return &ts
+	}
+
+	// ordered the way consumeLots reads them, expires_at ascending with
+	// lots that never expire last
+	lots := []models.InventoryLot{
+		{ID: 1, Quantity: 5, ExpiresAt: at(-1)},
+		{ID: 2, Quantity: 3, ExpiresAt: at(1)},
+		{ID: 3, Quantity: 4, ExpiresAt: at(10)},
+		{ID: 4, Quantity: 2},
+	}
+
+	tests := []struct {
+		name         string
+		onHand       int64
+		quantity     int64
+		expiredFirst bool
+		wantTaken    []int64
+		wantExpired  int64
+		wantErr      error
+	}{
+		{
+			name:        "earliest expiring sellable lot goes first",
+			onHand:      20,
+			quantity:    2,
+			wantTaken:   []int64{0, 2, 0, 0},
+			wantExpired: 5,
+		},
+		{
+			name:        "spills over into the next lot",
+			onHand:      20,
+			quantity:    5,
+			wantTaken:   []int64{0, 3, 2, 0},
+			wantExpired: 5,
+		},
+		{
+			name:        "lots without an expiry go last",
+			onHand:      20,
+			quantity:    9,
+			wantTaken:   []int64{0, 3, 4, 2},
+			wantExpired: 5,
+		},
+		{
+			name:        "the rest comes out of unlotted stock",
+			onHand:      20,
+			quantity:    12,
+			wantTaken:   []int64{0, 3, 4, 2},
+			wantExpired: 5,
+		},
+		{
+			name:         "expired first drains expired lots before sellable ones",
+			onHand:       20,
+			quantity:     7,
+			expiredFirst: true,
+			wantTaken:    []int64{5, 2, 0, 0},
+			wantExpired:  0,
+		},
+		{
+			name:        "expired units are never sold",
+			onHand:      10,
+			quantity:    6,
+			wantExpired: 5,
+			wantErr:     ErrInsufficientStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taken, expired, err := planLotConsumption(lots, tt.onHand, tt.quantity, tt.expiredFirst, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if expired != tt.wantExpired {
+				t.Errorf("expired = %d, want %d", expired, tt.wantExpired)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(taken, tt.wantTaken) {
+				t.Errorf("taken = %v, want %v", taken, tt.wantTaken)
+			}
+		})
+	}
+}
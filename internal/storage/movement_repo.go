@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MovementInfo carries who changed stock and why, it is copied onto every
+// ledger row written for the change
+type MovementInfo struct {
+	Reason    string
+	Actor     string
+	Reference string
+	// Serials names the units moved when the sku is serialized
+	Serials []string
+	// FromStatus, ToStatus and MovedQuantity describe a move between
+	// condition buckets, which may leave the sellable quantity untouched
+	FromStatus    string
+	ToStatus      string
+	MovedQuantity int64
+	// UnsellableDelta is what the change adds to the damaged, quarantine and
+	// expired buckets, the caller updates those after the ledger row is written
+	UnsellableDelta int64
+}
+
+// stockChange is a signed quantity change against the inventory row of a
+// sku at a hub
+type stockChange struct {
+	TenantID        string
+	SellerID        string
+	HubID           int
+	SKUID           int
+	Delta           int64
+	CreateIfMissing bool
+	// LocationID books the change against a bin as well as the hub total
+	LocationID *int
+	// RespectReservations keeps a decrement from eating into units held by
+	// active reservations
+	RespectReservations bool
+	// ConsumeExpiredLots lets a decrement take units out of expired lots, they
+	// go first
+	ConsumeExpiredLots bool
+	// ExpectedVersion rejects the change when the row has moved on since the
+	// caller read it, zero expects the row not to exist yet
+	ExpectedVersion *int64
+	// AllowNegative lets a decrement take the row below zero, lots and bins
+	// only give up what they hold
+	AllowNegative bool
+}
+
+type MovementRepo struct {
+	DB *Postgres
+}
+
+func NewMovementRepo(db *Postgres) *MovementRepo {
+	return &MovementRepo{
+		DB: db,
+	}
+}
+
+func (r *MovementRepo) GetBySKUHub(ctx context.Context, skuID, hubID int, limit, offset int) ([]models.InventoryMovement, int64, error) {
+	logTag := "[MovementRepo][GetBySKUHub]"
+	log.InfofWithContext(ctx, logTag+" getting movements from db", "sku_id", skuID, "hub_id", hubID)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Model(&models.InventoryMovement{}).Where("sku_id = ? AND hub_id = ?", skuID, hubID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when counting movements %v", err)
+		return nil, 0, fmt.Errorf("error when counting movements %v", err)
+	}
+
+	var movements []models.InventoryMovement
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting movements %v", err)
+		return nil, 0, fmt.Errorf("error when getting movements %v", err)
+	}
+
+	return movements, total, nil
+}
+
+// GetQuantitiesAsOf replays the ledger to return the on hand and sellable
+// quantity of each sku of the seller at the hub as it stood at asOf
+func (r *MovementRepo) GetQuantitiesAsOf(ctx context.Context, hubID int, sellerID string, skuCodes []string, asOf time.Time) ([]models.SKUQuantitySnapshot, error) {
+	logTag := "[MovementRepo][GetQuantitiesAsOf]"
+	log.InfofWithContext(ctx, logTag+" getting quantities as of timestamp", "hub_id", hubID, "seller_id", sellerID, "as_of", asOf)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Table("inventory_movements AS m").
+		Select("DISTINCT ON (m.sku_id) s.sku_code AS sku, m.on_hand_after AS quantity, m.quantity_after AS sellable, m.created_at AS last_movement_at").
+		Joins("JOIN skus AS s ON s.id = m.sku_id").
+		Where("m.hub_id = ? AND m.seller_id = ? AND m.created_at <= ?", hubID, sellerID, asOf)
+
+	if len(skuCodes) > 0 {
+		query = query.Where("s.sku_code IN ?", skuCodes)
+	}
+
+	var snapshots []models.SKUQuantitySnapshot
+	if err := query.Order("m.sku_id, m.created_at DESC, m.id DESC").Scan(&snapshots).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting quantities as of timestamp %v", err)
+		return nil, fmt.Errorf("error when getting quantities as of timestamp %v", err)
+	}
+
+	return snapshots, nil
+}
+
+// applyStockDelta locks the inventory row, applies the delta and appends the
+// ledger entry, it must run inside the caller's transaction
+func applyStockDelta(tx *gorm.DB, change stockChange, info MovementInfo) (*models.Inventory, *models.InventoryMovement, error) {
+	var inventory models.Inventory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ?", change.SKUID, change.HubID).
+		First(&inventory).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !change.CreateIfMissing || change.Delta < 0 {
+			return nil, nil, fmt.Errorf("%w for hub_id=%d, sku_id=%d", ErrInventoryNotFound, change.HubID, change.SKUID)
+		}
+		if change.ExpectedVersion != nil && *change.ExpectedVersion != 0 {
+			return nil, nil, fmt.Errorf("%w: expected version %d, row does not exist", ErrVersionConflict, *change.ExpectedVersion)
+		}
+		inventory = models.Inventory{
+			TenantID: change.TenantID,
+			SellerID: change.SellerID,
+			HubID:    change.HubID,
+			SKUID:    change.SKUID,
+		}
+		if err := tx.Create(&inventory).Error; err != nil {
+			return nil, nil, fmt.Errorf("error when creating inventory row %v", err)
+		}
+	case err != nil:
+		return nil, nil, fmt.Errorf("error when locking inventory row %v", err)
+	case change.SellerID != "" && inventory.SellerID != change.SellerID:
+		return nil, nil, fmt.Errorf("%w for hub_id=%d, seller_id=%s, sku_id=%d", ErrInventoryNotFound, change.HubID, change.SellerID, change.SKUID)
+	case change.ExpectedVersion != nil && inventory.Version != *change.ExpectedVersion:
+		return nil, nil, fmt.Errorf("%w: expected version %d, row is at %d", ErrVersionConflict, *change.ExpectedVersion, inventory.Version)
+	}
+
+	before := inventory.Quantity
+	after := before + change.Delta
+	if after < 0 && !change.AllowNegative {
+		return nil, nil, fmt.Errorf("%w: on hand %d, change %d", ErrInsufficientStock, before, change.Delta)
+	}
+
+	if change.Delta < 0 {
+		taken := -change.Delta
+		if after < 0 {
+			taken = max(before, 0)
+		}
+		expired, err := consumeLots(tx, &inventory, before, taken, change.ConsumeExpiredLots)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if change.RespectReservations {
+			reserved, err := heldQuantity(tx, change.SKUID, change.HubID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := checkReservedStock(after-expired, reserved, change.Delta); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if change.Delta != 0 {
+		if err := tx.Model(&inventory).Updates(map[string]interface{}{
+			"quantity": after,
+			"version":  gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return nil, nil, fmt.Errorf("error when updating inventory quantity %v", err)
+		}
+		inventory.Version++
+	}
+	inventory.Quantity = after
+
+	if change.LocationID != nil {
+		if err := adjustBin(tx, &inventory, *change.LocationID, change.Delta); err != nil {
+			return nil, nil, err
+		}
+	} else if change.Delta < 0 {
+		if err := shrinkBinsTo(tx, &inventory); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	movement, err := recordMovement(tx, &inventory, before, change.LocationID, info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := applySerials(tx, &inventory, movement, info.Serials); err != nil {
+		return nil, nil, err
+	}
+
+	return &inventory, movement, nil
+}
+
+// checkReservedStock refuses a decrement that would leave fewer sellable
+// units than active reservations hold
+func checkReservedStock(left, reserved, delta int64) error {
+	if left < reserved {
+		return fmt.Errorf("%w: %d reserved, %d would be left, change %d", ErrInsufficientStock, reserved, left, delta)
+	}
+	return nil
+}
+
+// recordMovement appends a ledger row for an inventory row whose quantity
+// moved from before to its current value
+func recordMovement(tx *gorm.DB, inventory *models.Inventory, before int64, locationID *int, info MovementInfo) (*models.InventoryMovement, error) {
+	movement := &models.InventoryMovement{
+		TenantID:       inventory.TenantID,
+		SellerID:       inventory.SellerID,
+		HubID:          inventory.HubID,
+		SKUID:          inventory.SKUID,
+		LocationID:     locationID,
+		Reason:         info.Reason,
+		Actor:          info.Actor,
+		Reference:      info.Reference,
+		Delta:          inventory.Quantity - before,
+		QuantityBefore: before,
+		QuantityAfter:  inventory.Quantity,
+		OnHandAfter:    inventory.OnHand() + info.UnsellableDelta,
+		FromStatus:     info.FromStatus,
+		ToStatus:       info.ToStatus,
+		MovedQuantity:  info.MovedQuantity,
+	}
+
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, fmt.Errorf("error when recording inventory movement %v", err)
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,528 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pendingPicksJoin aggregates units already promised to pending pick tasks
+// per bin so a new wave does not send two pickers after the same units
+const pendingPicksJoin = `LEFT JOIN (
+		SELECT location_id, sku_id, SUM(quantity) AS pending
+		FROM pick_tasks
+		WHERE status = 'pending' AND location_id IS NOT NULL
+		GROUP BY location_id, sku_id
+	) AS p ON p.location_id = b.location_id AND p.sku_id = b.sku_id`
+
+// waveLine is an allocated order line waiting to be picked
+type waveLine struct {
+	ID           int64
+	AllocationID int64
+	TenantID     string
+	SellerID     string
+	HubID        int
+	OrderRef     string
+	SKUID        int    `gorm:"column:sku_id"`
+	SKUCode      string `gorm:"column:sku_code"`
+	Quantity     int64
+	// ReservationID, ReservationStatus and ExpiresAt describe the hold the
+	// line was allocated with
+	ReservationID     int64
+	ReservationStatus string
+	ExpiresAt         time.Time
+}
+
+// lapsed reports whether the line's reservation no longer holds its units
+func (l waveLine) lapsed(now time.Time) bool {
+	return l.ReservationStatus != models.ReservationStatusHeld || !l.ExpiresAt.After(now)
+}
+
+// pickBin is the stock of a sku in a bin that is still free to pick
+type pickBin struct {
+	SKUID        int `gorm:"column:sku_id"`
+	LocationID   int
+	LocationCode string
+	WalkSequence *int
+	Quantity     int64
+}
+
+type PickingRepo struct {
+	DB *Postgres
+}
+
+func NewPickingRepo(db *Postgres) *PickingRepo {
+	return &PickingRepo{
+		DB: db,
+	}
+}
+
+// CreateWave batches the allocated order lines of the hub that are not in a
+// wave yet, optionally only those of the given allocations and at most
+// maxOrders orders. Lines whose reservation expired are held again until
+// holdUntil, those the hub can no longer cover are reported on the wave as
+// unreserved. Every line becomes one task per bin it is picked from, tasks
+// are numbered along the bin walk sequence
+func (r *PickingRepo) CreateWave(ctx context.Context, wave *models.PickWave, allocationIDs []int64, maxOrders int, holdUntil time.Time, info MovementInfo) error {
+	logTag := "[PickingRepo][CreateWave]"
+	log.InfofWithContext(ctx, logTag+" creating pick wave in db", "hub_id", wave.HubID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Table("order_allocation_lines AS l").
+			Select("l.id, l.allocation_id, a.tenant_id, a.seller_id, l.hub_id, a.order_ref, l.sku_id, l.sku_code, l.quantity, "+
+				"res.id AS reservation_id, res.status AS reservation_status, res.expires_at").
+			Joins("JOIN order_allocations AS a ON a.id = l.allocation_id").
+			Joins("JOIN inventory_reservations AS res ON res.id = l.reservation_id").
+			Where("a.tenant_id = ? AND l.hub_id = ? AND l.wave_id IS NULL AND res.status IN ?",
+				wave.TenantID, wave.HubID, []string{models.ReservationStatusHeld, models.ReservationStatusExpired})
+		if len(allocationIDs) > 0 {
+			query = query.Where("a.id IN ?", allocationIDs)
+		}
+
+		var candidates []waveLine
+		if err := query.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "l"}}).
+			Order("a.created_at, a.id, l.id").
+			Scan(&candidates).Error; err != nil {
+			return fmt.Errorf("error when getting allocated lines %v", err)
+		}
+
+		now := time.Now()
+		orders := make(map[int64]bool)
+		var lines []waveLine
+		for _, line := range candidates {
+			if !orders[line.AllocationID] {
+				if maxOrders > 0 && len(orders) == maxOrders {
+					continue
+				}
+				orders[line.AllocationID] = true
+			}
+			if line.lapsed(now) {
+				reason, err := reholdLine(tx, &line, holdUntil, info)
+				if err != nil {
+					return err
+				}
+				if reason != "" {
+					wave.Unreserved = append(wave.Unreserved, models.UnreservedLine{
+						AllocationID:     line.AllocationID,
+						AllocationLineID: line.ID,
+						OrderRef:         line.OrderRef,
+						SKUCode:          line.SKUCode,
+						Quantity:         line.Quantity,
+						Reason:           reason,
+					})
+					continue
+				}
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) == 0 {
+			if len(wave.Unreserved) > 0 {
+				return fmt.Errorf("%w: %d allocated lines at hub %d lost their reservation and could not be held again",
+					ErrInsufficientStock, len(wave.Unreserved), wave.HubID)
+			}
+			return fmt.Errorf("no allocated order lines waiting to be picked at hub %d", wave.HubID)
+		}
+
+		skuIDs := make([]int, 0, len(lines))
+		lineIDs := make([]int64, 0, len(lines))
+		for _, line := range lines {
+			skuIDs = append(skuIDs, line.SKUID)
+			lineIDs = append(lineIDs, line.ID)
+		}
+
+		var bins []pickBin
+		if err := tx.Table("bin_inventory AS b").
+			Select("b.sku_id, b.location_id, loc.code AS location_code, loc.walk_sequence, b.quantity - COALESCE(p.pending, 0) AS quantity").
+			Joins("JOIN hub_locations AS loc ON loc.id = b.location_id").
+			Joins(pendingPicksJoin).
+			Where("b.hub_id = ? AND b.sku_id IN ? AND b.quantity > 0", wave.HubID, skuIDs).
+			Order("loc.walk_sequence NULLS LAST, loc.code").
+			Scan(&bins).Error; err != nil {
+			return fmt.Errorf("error when getting bin stock %v", err)
+		}
+
+		wave.Status = models.WaveStatusOpen
+		wave.Tasks = planPickTasks(lines, bins)
+
+		if err := tx.Create(wave).Error; err != nil {
+			return fmt.Errorf("error when creating pick wave in db %v", err)
+		}
+
+		if err := tx.Model(&models.OrderAllocationLine{}).
+			Where("id IN ?", lineIDs).
+			Update("wave_id", wave.ID).Error; err != nil {
+			return fmt.Errorf("error when assigning lines to wave %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating pick wave %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" pick wave created successfully", "id", wave.ID, "tasks", len(wave.Tasks))
+	return nil
+}
+
+// reholdLine replaces the lapsed reservation of an allocated line with a new
+// one held until holdUntil. A line the hub can no longer cover keeps its old
+// reservation and the reason is returned instead of an error
+func reholdLine(tx *gorm.DB, line *waveLine, holdUntil time.Time, info MovementInfo) (string, error) {
+	if line.ReservationStatus == models.ReservationStatusHeld {
+		if err := tx.Model(&models.InventoryReservation{}).
+			Where("id = ?", line.ReservationID).
+			Update("status", models.ReservationStatusExpired).Error; err != nil {
+			return "", fmt.Errorf("error when expiring reservation %v", err)
+		}
+	}
+
+	reservation := &models.InventoryReservation{
+		TenantID:  line.TenantID,
+		SellerID:  line.SellerID,
+		HubID:     line.HubID,
+		SKUID:     line.SKUID,
+		OrderRef:  line.OrderRef,
+		Quantity:  line.Quantity,
+		ExpiresAt: holdUntil,
+	}
+	if err := reserveStock(tx, reservation, info); err != nil {
+		if errors.Is(err, ErrInsufficientStock) || errors.Is(err, ErrInventoryNotFound) || errors.Is(err, ErrReservationExists) {
+			return err.Error(), nil
+		}
+		return "", err
+	}
+
+	if err := tx.Model(&models.OrderAllocationLine{}).
+		Where("id = ?", line.ID).
+		Update("reservation_id", reservation.ID).Error; err != nil {
+		return "", fmt.Errorf("error when updating line reservation %v", err)
+	}
+	line.ReservationID = reservation.ID
+	line.ReservationStatus = models.ReservationStatusHeld
+	line.ExpiresAt = holdUntil
+	return "", nil
+}
+
+func (r *PickingRepo) GetWave(ctx context.Context, tenantID string, id int64) (*models.PickWave, error) {
+	logTag := "[PickingRepo][GetWave]"
+	log.InfofWithContext(ctx, logTag+" getting pick wave by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var wave models.PickWave
+	if err := db.Preload("Tasks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&wave).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaveNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting pick wave %v", err)
+		return nil, fmt.Errorf("error when getting pick wave %v", err)
+	}
+
+	return &wave, nil
+}
+
+// ConfirmPick records what the picker took for a task. The task's share of
+// the order reservation is let go and the picked units leave the bin, units
+// that were not found are flagged short on the order line so they can be
+// sourced from another hub
+func (r *PickingRepo) ConfirmPick(ctx context.Context, tenantID string, taskID int64, picked int64, info MovementInfo) (*models.PickTask, error) {
+	logTag := "[PickingRepo][ConfirmPick]"
+	log.InfofWithContext(ctx, logTag+" confirming pick task", "id", taskID, "picked", picked)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var task models.PickTask
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND wave_id IN (SELECT id FROM pick_waves WHERE tenant_id = ?)", taskID, tenantID).
+			First(&task).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPickTaskNotFound
+			}
+			return fmt.Errorf("error when locking pick task %v", err)
+		}
+
+		if task.Status != models.PickTaskStatusPending {
+			return fmt.Errorf("%w: task is already %s", ErrInvalidTransition, task.Status)
+		}
+		if picked > task.Quantity {
+			return fmt.Errorf("%w: task %d asks for %d, picked %d", ErrQuantityExceeded, task.ID, task.Quantity, picked)
+		}
+
+		wave, err := lockWave(tx, tenantID, task.WaveID)
+		if err != nil {
+			return err
+		}
+		if wave.Status != models.WaveStatusOpen && wave.Status != models.WaveStatusPicking {
+			return fmt.Errorf("%w: cannot pick in a %s wave", ErrInvalidTransition, wave.Status)
+		}
+
+		var allocation models.OrderAllocation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", task.AllocationID).
+			First(&allocation).Error; err != nil {
+			return fmt.Errorf("error when locking order allocation %v", err)
+		}
+
+		var line models.OrderAllocationLine
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", task.AllocationLineID).
+			First(&line).Error; err != nil {
+			return fmt.Errorf("error when locking allocation line %v", err)
+		}
+
+		if err := releasePickedHold(tx, &line, &task, picked); err != nil {
+			return err
+		}
+
+		if picked > 0 {
+			info.Reason = models.MovementReasonPick
+			if _, _, err := applyStockDelta(tx, stockChange{
+				TenantID:            allocation.TenantID,
+				SellerID:            allocation.SellerID,
+				HubID:               wave.HubID,
+				SKUID:               task.SKUID,
+				Delta:               -picked,
+				LocationID:          task.LocationID,
+				RespectReservations: true,
+			}, withReference(info, fmt.Sprintf("wave:%d", wave.ID))); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		task.PickedQuantity = picked
+		task.ShortQuantity = task.Quantity - picked
+		task.Status = models.PickTaskStatusPicked
+		task.ConfirmedBy = info.Actor
+		task.ConfirmedAt = &now
+
+		if task.ShortQuantity > 0 {
+			task.Status = models.PickTaskStatusShort
+
+			if err := tx.Model(&line).Update("short_quantity", line.ShortQuantity+task.ShortQuantity).Error; err != nil {
+				return fmt.Errorf("error when flagging short line %v", err)
+			}
+			if allocation.Status == models.AllocationStatusAllocated {
+				if err := tx.Model(&allocation).Update("status", models.AllocationStatusPartial).Error; err != nil {
+					return fmt.Errorf("error when flagging short order %v", err)
+				}
+			}
+		}
+
+		if err := tx.Save(&task).Error; err != nil {
+			return fmt.Errorf("error when updating pick task %v", err)
+		}
+
+		var pending int64
+		if err := tx.Model(&models.PickTask{}).
+			Where("wave_id = ? AND status = ?", wave.ID, models.PickTaskStatusPending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("error when counting pending tasks %v", err)
+		}
+
+		updates := map[string]interface{}{"status": models.WaveStatusPicking}
+		if pending == 0 {
+			updates = map[string]interface{}{"status": models.WaveStatusCompleted, "completed_at": now}
+		}
+		return tx.Model(wave).Updates(updates).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when confirming pick task %v", err)
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// CancelWave drops a wave nobody has started picking, its order lines can be
+// batched again
+func (r *PickingRepo) CancelWave(ctx context.Context, tenantID string, id int64) (*models.PickWave, error) {
+	logTag := "[PickingRepo][CancelWave]"
+	log.InfofWithContext(ctx, logTag+" cancelling pick wave", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var wave *models.PickWave
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		wave, err = lockWave(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if wave.Status != models.WaveStatusOpen {
+			return fmt.Errorf("%w: cannot cancel a %s wave", ErrInvalidTransition, wave.Status)
+		}
+
+		if err := tx.Where("wave_id = ?", id).Delete(&models.PickTask{}).Error; err != nil {
+			return fmt.Errorf("error when deleting pick tasks %v", err)
+		}
+
+		if err := tx.Model(&models.OrderAllocationLine{}).
+			Where("wave_id = ?", id).
+			Update("wave_id", nil).Error; err != nil {
+			return fmt.Errorf("error when releasing wave lines %v", err)
+		}
+
+		wave.Status = models.WaveStatusCancelled
+		return tx.Model(wave).Update("status", wave.Status).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when cancelling pick wave %v", err)
+		return nil, err
+	}
+
+	return wave, nil
+}
+
+// planPickTasks splits every line over the bins holding its sku in walk
+// order, what the bins cannot cover is picked from unassigned stock. The
+// tasks are numbered in the order a picker walks past them
+func planPickTasks(lines []waveLine, bins []pickBin) []models.PickTask {
+	type plannedTask struct {
+		task         models.PickTask
+		walkSequence *int
+	}
+
+	binsBySKU := make(map[int][]*pickBin)
+	for i := range bins {
+		binsBySKU[bins[i].SKUID] = append(binsBySKU[bins[i].SKUID], &bins[i])
+	}
+
+	var planned []plannedTask
+	for _, line := range lines {
+		base := models.PickTask{
+			AllocationID:     line.AllocationID,
+			AllocationLineID: line.ID,
+			OrderRef:         line.OrderRef,
+			SKUID:            line.SKUID,
+			SKUCode:          line.SKUCode,
+			Status:           models.PickTaskStatusPending,
+		}
+
+		remaining := line.Quantity
+		for _, bin := range binsBySKU[line.SKUID] {
+			if remaining == 0 {
+				break
+			}
+			take := min(bin.Quantity, remaining)
+			if take <= 0 {
+				continue
+			}
+
+			task := base
+			locationID := bin.LocationID
+			task.LocationID = &locationID
+			task.LocationCode = bin.LocationCode
+			task.Quantity = take
+			planned = append(planned, plannedTask{task: task, walkSequence: bin.WalkSequence})
+
+			bin.Quantity -= take
+			remaining -= take
+		}
+
+		if remaining > 0 {
+			task := base
+			task.Quantity = remaining
+			planned = append(planned, plannedTask{task: task})
+		}
+	}
+
+	sort.SliceStable(planned, func(i, j int) bool {
+		a, b := planned[i], planned[j]
+		if (a.task.LocationID == nil) != (b.task.LocationID == nil) {
+			return b.task.LocationID == nil
+		}
+		switch {
+		case a.walkSequence != nil && b.walkSequence == nil:
+			return true
+		case a.walkSequence == nil && b.walkSequence != nil:
+			return false
+		case a.walkSequence != nil && *a.walkSequence != *b.walkSequence:
+			return *a.walkSequence < *b.walkSequence
+		}
+		if a.task.LocationCode != b.task.LocationCode {
+			return a.task.LocationCode < b.task.LocationCode
+		}
+		if a.task.SKUCode != b.task.SKUCode {
+			return a.task.SKUCode < b.task.SKUCode
+		}
+		return a.task.OrderRef < b.task.OrderRef
+	})
+
+	tasks := make([]models.PickTask, 0, len(planned))
+	for i, p := range planned {
+		p.task.Sequence = i + 1
+		tasks = append(tasks, p.task)
+	}
+	return tasks
+}
+
+// releasePickedHold takes the units of a task out of the order reservation
+// before they are decremented, the reservation closes with its last task.
+// An expired reservation holds nothing anymore and is left alone
+func releasePickedHold(tx *gorm.DB, line *models.OrderAllocationLine, task *models.PickTask, picked int64) error {
+	if line.ReservationID == nil {
+		return nil
+	}
+
+	var reservation models.InventoryReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", *line.ReservationID).
+		First(&reservation).Error; err != nil {
+		return fmt.Errorf("error when locking reservation %v", err)
+	}
+
+	if reservation.Status != models.ReservationStatusHeld || !reservation.ExpiresAt.After(time.Now()) {
+		return nil
+	}
+
+	if reservation.Quantity > task.Quantity {
+		if err := tx.Model(&reservation).Update("quantity", reservation.Quantity-task.Quantity).Error; err != nil {
+			return fmt.Errorf("error when reducing reservation %v", err)
+		}
+		return nil
+	}
+
+	var pickedBefore int64
+	if err := tx.Model(&models.PickTask{}).
+		Select("COALESCE(SUM(picked_quantity), 0)").
+		Where("allocation_line_id = ? AND id <> ?", line.ID, task.ID).
+		Scan(&pickedBefore).Error; err != nil {
+		return fmt.Errorf("error when summing picked units %v", err)
+	}
+
+	status := models.ReservationStatusCommitted
+	if picked+pickedBefore == 0 {
+		status = models.ReservationStatusReleased
+	}
+	if err := tx.Model(&reservation).Update("status", status).Error; err != nil {
+		return fmt.Errorf("error when closing reservation %v", err)
+	}
+	return nil
+}
+
+func lockWave(tx *gorm.DB, tenantID string, id int64) (*models.PickWave, error) {
+	var wave models.PickWave
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&wave).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWaveNotFound
+		}
+		return nil, fmt.Errorf("error when locking pick wave %v", err)
+	}
+	return &wave, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPlanPickTasks(t *testing.T) {
+	seq := func(n int) *int { return &n }
+
+	// bins arrive in walk order the way CreateWave reads them
+	bins := func() []pickBin {
+		return []pickBin{
+			{SKUID: 1, LocationID: 10, LocationCode: "A-01", WalkSequence: seq(1), Quantity: 4},
+			{SKUID: 2, LocationID: 11, LocationCode: "A-02", WalkSequence: seq(2), Quantity: 6},
+			{SKUID: 1, LocationID: 12, LocationCode: "B-01", WalkSequence: seq(5), Quantity: 3},
+			{SKUID: 2, LocationID: 13, LocationCode: "Z-01", Quantity: 5},
+		}
+	}
+	line := func(id int64, orderRef string, skuID int, quantity int64) waveLine {
+		return waveLine{ID: id, AllocationID: id, OrderRef: orderRef, SKUID: skuID, SKUCode: fmt.Sprintf("SKU-%d", skuID), Quantity: quantity}
+	}
+
+	tests := []struct {
+		name  string
+		lines []waveLine
+		want  []string
+	}{
+		{
+			name:  "a line the first bin covers is one task",
+			lines: []waveLine{line(1, "ORD-1", 1, 3)},
+			want:  []string{"1 ORD-1 SKU-1 3@A-01"},
+		},
+		{
+			name:  "a line spills over into the next bin on the walk",
+			lines: []waveLine{line(1, "ORD-1", 1, 6)},
+			want:  []string{"1 ORD-1 SKU-1 4@A-01", "2 ORD-1 SKU-1 2@B-01"},
+		},
+		{
+			name:  "what the bins cannot cover is picked from unassigned stock last",
+			lines: []waveLine{line(1, "ORD-1", 1, 9), line(2, "ORD-2", 2, 1)},
+			want:  []string{"1 ORD-1 SKU-1 4@A-01", "2 ORD-2 SKU-2 1@A-02", "3 ORD-1 SKU-1 3@B-01", "4 ORD-1 SKU-1 2@-"},
+		},
+		{
+			name:  "orders share bins and tasks follow the walk across orders",
+			lines: []waveLine{line(1, "ORD-1", 1, 3), line(2, "ORD-2", 1, 3)},
+			want:  []string{"1 ORD-1 SKU-1 3@A-01", "2 ORD-2 SKU-1 1@A-01", "3 ORD-2 SKU-1 2@B-01"},
+		},
+		{
+			name:  "bins without a walk sequence come after sequenced ones",
+			lines: []waveLine{line(1, "ORD-1", 2, 8)},
+			want:  []string{"1 ORD-1 SKU-2 6@A-02", "2 ORD-1 SKU-2 2@Z-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := planPickTasks(tt.lines, bins())
+
+			got := make([]string, 0, len(tasks))
+			for _, task := range tasks {
+				location := "-"
+				if task.LocationID != nil {
+					location = task.LocationCode
+				}
+				got = append(got, fmt.Sprintf("%d %s %s %d@%s", task.Sequence, task.OrderRef, task.SKUCode, task.Quantity, location))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tasks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+)
+
+// outboundReasons are the ledger reasons that count as demand, transfers,
+// shrinkage and status changes move stock without selling it
+var outboundReasons = []string{
+	models.MovementReasonAdjustment,
+	models.MovementReasonSale,
+	models.MovementReasonReservationCommit,
+	models.MovementReasonPick,
+}
+
+// ReplenishmentFilter narrows a replenishment report down, only TenantID is
+// required
+type ReplenishmentFilter struct {
+	TenantID string
+	HubID    int
+	SellerID string
+	SKUCodes []string
+}
+
+// GetOutboundVelocity sums the outbound decrements since the given time for
+// every inventory row matching filter, rows without any come back with zero
+func (r *MovementRepo) GetOutboundVelocity(ctx context.Context, filter ReplenishmentFilter, since time.Time) ([]models.ReplenishmentLine, error) {
+	logTag := "[MovementRepo][GetOutboundVelocity]"
+	log.InfofWithContext(ctx, logTag+" getting outbound velocity", "tenant_id", filter.TenantID, "hub_id", filter.HubID, "since", since)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	query := db.Table("inventory AS i").
+		Select("i.hub_id, i.seller_id, i.sku_id, s.sku_code, s.name AS sku_name, i.quantity, COALESCE(m.outbound, 0) AS outbound_units").
+		Joins("JOIN hubs AS h ON h.id = i.hub_id").
+		Joins("JOIN skus AS s ON s.id = i.sku_id").
+		Joins("LEFT JOIN (SELECT sku_id, hub_id, -SUM(delta) AS outbound FROM inventory_movements WHERE delta < 0 AND reason IN ? AND created_at >= ? GROUP BY sku_id, hub_id) AS m ON m.sku_id = i.sku_id AND m.hub_id = i.hub_id", outboundReasons, since).
+		Where("h.tenant_id = ?", filter.TenantID)
+
+	if filter.HubID != 0 {
+		query = query.Where("i.hub_id = ?", filter.HubID)
+	}
+	if filter.SellerID != "" {
+		query = query.Where("i.seller_id = ?", filter.SellerID)
+	}
+	if len(filter.SKUCodes) > 0 {
+		query = query.Where("s.sku_code IN ?", filter.SKUCodes)
+	}
+
+	var lines []models.ReplenishmentLine
+	if err := query.Order("i.hub_id, s.sku_code").Scan(&lines).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting outbound velocity %v", err)
+		return nil, fmt.Errorf("error when getting outbound velocity %v", err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeReservationsJoin aggregates units held by unexpired reservations per
+// (sku_id, hub_id) so reads can expose reserved and available stock
+const activeReservationsJoin = `LEFT JOIN (
+		SELECT sku_id, hub_id, SUM(quantity) AS reserved
+		FROM inventory_reservations
+		WHERE status = 'held' AND expires_at > now()
+		GROUP BY sku_id, hub_id
+	) AS r ON r.sku_id = i.sku_id AND r.hub_id = i.hub_id`
+
+type ReservationRepo struct {
+	DB *Postgres
+}
+
+func NewReservationRepo(db *Postgres) *ReservationRepo {
+	return &ReservationRepo{
+		DB: db,
+	}
+}
+
+func (r *ReservationRepo) Reserve(ctx context.Context, reservation *models.InventoryReservation, info MovementInfo) error {
+	logTag := "[ReservationRepo][Reserve]"
+	log.InfofWithContext(ctx, logTag+" reserving inventory in db", "reservation", reservation)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return reserveStock(tx, reservation, info)
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when reserving inventory %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" reservation created successfully", "id", reservation.ID)
+	return nil
+}
+
+func (r *ReservationRepo) Release(ctx context.Context, tenantID string, id int64, info MovementInfo) (*models.InventoryReservation, error) {
+	logTag := "[ReservationRepo][Release]"
+	log.InfofWithContext(ctx, logTag+" releasing reservation in db", "tenant_id", tenantID, "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var reservation *models.InventoryReservation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockHeldReservation(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		reservation.Status = models.ReservationStatusReleased
+		if err := tx.Save(reservation).Error; err != nil {
+			return fmt.Errorf("error when releasing reservation %v", err)
+		}
+
+		info.Reason = models.MovementReasonReservationRelease
+		_, _, err = applyStockDelta(tx, reservationChange(reservation, 0), withReference(info, reservation.OrderRef))
+		return err
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when releasing reservation %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" reservation released successfully", "id", id)
+	return reservation, nil
+}
+
+// Commit turns a held reservation into a real decrement of inventory.quantity
+func (r *ReservationRepo) Commit(ctx context.Context, tenantID string, id int64, info MovementInfo) (*models.InventoryReservation, error) {
+	logTag := "[ReservationRepo][Commit]"
+	log.InfofWithContext(ctx, logTag+" committing reservation in db", "tenant_id", tenantID, "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var reservation *models.InventoryReservation
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = lockHeldReservation(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		info.Reason = models.MovementReasonReservationCommit
+		if _, _, err := applyStockDelta(tx, reservationChange(reservation, -reservation.Quantity), withReference(info, reservation.OrderRef)); err != nil {
+			return err
+		}
+
+		reservation.Status = models.ReservationStatusCommitted
+		if err := tx.Save(reservation).Error; err != nil {
+			return fmt.Errorf("error when committing reservation %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when committing reservation %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" reservation committed successfully", "id", id)
+	return reservation, nil
+}
+
+func (r *ReservationRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.InventoryReservation, error) {
+	logTag := "[ReservationRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting reservation by id", "tenant_id", tenantID, "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var reservation models.InventoryReservation
+	if err := db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting reservation %v", err)
+		return nil, fmt.Errorf("error when getting reservation %v", err)
+	}
+
+	return &reservation, nil
+}
+
+func (r *ReservationRepo) GetByOrderRef(ctx context.Context, tenantID, orderRef string) ([]models.InventoryReservation, error) {
+	logTag := "[ReservationRepo][GetByOrderRef]"
+	log.InfofWithContext(ctx, logTag+" getting reservations by order ref", "tenant_id", tenantID, "order_ref", orderRef)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var reservations []models.InventoryReservation
+	if err := db.Where("tenant_id = ? AND order_ref = ?", tenantID, orderRef).
+		Order("id").
+		Find(&reservations).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting reservations %v", err)
+		return nil, fmt.Errorf("error when getting reservations %v", err)
+	}
+
+	return reservations, nil
+}
+
+// ExpireStale flips held reservations past their ttl to expired, reads already
+// ignore them so this only keeps the status column honest
+func (r *ReservationRepo) ExpireStale(ctx context.Context, now time.Time) (int64, error) {
+	logTag := "[ReservationRepo][ExpireStale]"
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	result := db.Model(&models.InventoryReservation{}).
+		Where("status = ? AND expires_at <= ?", models.ReservationStatusHeld, now).
+		Update("status", models.ReservationStatusExpired)
+	if result.Error != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when expiring reservations %v", result.Error)
+		return 0, fmt.Errorf("error when expiring reservations %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// reserveStock holds units against the available stock of the inventory row,
+// callers run it inside their own transaction
+func reserveStock(tx *gorm.DB, reservation *models.InventoryReservation, info MovementInfo) error {
+	var inventory models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku_id = ? AND hub_id = ? AND seller_id = ?", reservation.SKUID, reservation.HubID, reservation.SellerID).
+		First(&inventory).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInventoryNotFound
+		}
+		return fmt.Errorf("error when locking inventory row %v", err)
+	}
+
+	var existing int64
+	if err := tx.Model(&models.InventoryReservation{}).
+		Where("tenant_id = ? AND order_ref = ? AND sku_id = ? AND hub_id = ? AND status = ? AND expires_at > now()",
+			reservation.TenantID, reservation.OrderRef, reservation.SKUID, reservation.HubID, models.ReservationStatusHeld).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("error when checking existing reservations %v", err)
+	}
+	if existing > 0 {
+		return ErrReservationExists
+	}
+
+	reserved, err := heldQuantity(tx, reservation.SKUID, reservation.HubID)
+	if err != nil {
+		return err
+	}
+
+	expired, err := expiredLotQuantity(tx, reservation.SKUID, reservation.HubID)
+	if err != nil {
+		return err
+	}
+
+	available := inventory.Quantity - reserved - expired
+	if available < reservation.Quantity {
+		return fmt.Errorf("%w: requested %d, available %d", ErrInsufficientStock, reservation.Quantity, available)
+	}
+
+	reservation.Status = models.ReservationStatusHeld
+	if err := tx.Create(reservation).Error; err != nil {
+		return fmt.Errorf("error when creating reservation in db %v", err)
+	}
+
+	info.Reason = models.MovementReasonReservationHold
+	_, err = recordMovement(tx, &inventory, inventory.Quantity, nil, withReference(info, reservation.OrderRef))
+	return err
+}
+
+func reservationChange(reservation *models.InventoryReservation, delta int64) stockChange {
+	return stockChange{
+		TenantID: reservation.TenantID,
+		SellerID: reservation.SellerID,
+		HubID:    reservation.HubID,
+		SKUID:    reservation.SKUID,
+		Delta:    delta,
+	}
+}
+
+// withReference defaults the ledger reference to the reservation's order
+func withReference(info MovementInfo, reference string) MovementInfo {
+	if info.Reference == "" {
+		info.Reference = reference
+	}
+	return info
+}
+
+func heldQuantity(tx *gorm.DB, skuID, hubID int) (int64, error) {
+	var reserved int64
+	if err := tx.Model(&models.InventoryReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("sku_id = ? AND hub_id = ? AND status = ? AND expires_at > now()", skuID, hubID, models.ReservationStatusHeld).
+		Scan(&reserved).Error; err != nil {
+		return 0, fmt.Errorf("error when summing reservations %v", err)
+	}
+	return reserved, nil
+}
+
+// lockHeldReservation locks a held reservation of the tenant, reservations of
+// other tenants are reported as not found
+func lockHeldReservation(tx *gorm.DB, tenantID string, id int64) (*models.InventoryReservation, error) {
+	var reservation models.InventoryReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("error when locking reservation %v", err)
+	}
+
+	if reservation.Status != models.ReservationStatusHeld {
+		return nil, fmt.Errorf("%w: status is %s", ErrReservationNotHeld, reservation.Status)
+	}
+	if !reservation.ExpiresAt.After(time.Now()) {
+		return nil, ErrReservationExpired
+	}
+
+	return &reservation, nil
+}
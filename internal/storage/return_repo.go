@@ -0,0 +1,402 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReturnReceipt is the quantity of one sku that arrived against a return
+type ReturnReceipt struct {
+	SKUID    int
+	SKUCode  string
+	Quantity int64
+}
+
+// ReturnDisposition decides the fate of received units of one sku, serialized
+// skus name every unit
+type ReturnDisposition struct {
+	SKUID       int
+	SKUCode     string
+	Disposition string
+	Quantity    int64
+	Serials     []string
+	Note        string
+}
+
+type ReturnRepo struct {
+	DB *Postgres
+}
+
+func NewReturnRepo(db *Postgres) *ReturnRepo {
+	return &ReturnRepo{
+		DB: db,
+	}
+}
+
+func (r *ReturnRepo) Create(ctx context.Context, rma *models.ReturnAuthorization, actor string) error {
+	logTag := "[ReturnRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating return authorization in db", "reference", rma.Reference)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ReturnAuthorization{}).
+			Where("tenant_id = ? AND seller_id = ? AND reference = ?", rma.TenantID, rma.SellerID, rma.Reference).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("error when checking existing returns %v", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("%w: %s", ErrReturnExists, rma.Reference)
+		}
+
+		rma.Status = models.ReturnStatusAuthorized
+		if err := tx.Create(rma).Error; err != nil {
+			return fmt.Errorf("error when creating return in db %v", err)
+		}
+
+		var units int64
+		for _, line := range rma.Lines {
+			units += line.AuthorizedQuantity
+		}
+		return recordReturnEvent(tx, rma, models.ReturnEvent{
+			Action:   models.ReturnEventCreated,
+			Quantity: units,
+			Actor:    actor,
+		})
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating return %v", err)
+		return err
+	}
+
+	log.InfofWithContext(ctx, logTag+" return created successfully", "id", rma.ID)
+	return nil
+}
+
+func (r *ReturnRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting return by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var rma models.ReturnAuthorization
+	if err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&rma).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting return %v", err)
+		return nil, fmt.Errorf("error when getting return %v", err)
+	}
+
+	return &rma, nil
+}
+
+// Receive books returned units as arrived at a hub. They wait for inspection
+// and do not touch inventory until they get a disposition
+func (r *ReturnRepo) Receive(ctx context.Context, tenantID string, id int64, hubID int, receipts []ReturnReceipt, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnRepo][Receive]"
+	log.InfofWithContext(ctx, logTag+" receiving return", "id", id, "hub_id", hubID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var rma *models.ReturnAuthorization
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rma, err = lockReturn(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if rma.Status != models.ReturnStatusAuthorized && rma.Status != models.ReturnStatusReceiving {
+			return fmt.Errorf("%w: cannot receive a %s return", ErrInvalidTransition, rma.Status)
+		}
+		if rma.HubID != nil && *rma.HubID != hubID {
+			return fmt.Errorf("%w: return is being received at hub %d", ErrInvalidTransition, *rma.HubID)
+		}
+
+		for _, receipt := range receipts {
+			line := returnLine(rma, receipt.SKUID)
+			if line == nil {
+				return fmt.Errorf("sku %s is not on return %s", receipt.SKUCode, rma.Reference)
+			}
+			if line.ReceivedQuantity+receipt.Quantity > line.AuthorizedQuantity {
+				return fmt.Errorf("%w: sku %s authorized %d, received %d, receiving %d", ErrQuantityExceeded, receipt.SKUCode, line.AuthorizedQuantity, line.ReceivedQuantity, receipt.Quantity)
+			}
+
+			line.ReceivedQuantity += receipt.Quantity
+			if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+				return fmt.Errorf("error when updating return line %v", err)
+			}
+
+			if err := recordReturnEvent(tx, rma, models.ReturnEvent{
+				Action:   models.ReturnEventReceived,
+				SKUCode:  receipt.SKUCode,
+				Quantity: receipt.Quantity,
+				Actor:    actor,
+			}); err != nil {
+				return err
+			}
+		}
+
+		rma.HubID = &hubID
+		rma.Status = models.ReturnStatusReceiving
+		return tx.Model(rma).Updates(map[string]interface{}{
+			"hub_id": rma.HubID,
+			"status": rma.Status,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when receiving return %v", err)
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+// Dispose records what happens to inspected units. Restocked units go back
+// into sellable stock and damaged units into the damaged bucket, both through
+// the ledger. Destroyed units and units going back to the seller never enter
+// inventory and are only tracked on the return
+func (r *ReturnRepo) Dispose(ctx context.Context, tenantID string, id int64, dispositions []ReturnDisposition, info MovementInfo) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnRepo][Dispose]"
+	log.InfofWithContext(ctx, logTag+" disposing returned units", "id", id, "lines", len(dispositions))
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var rma *models.ReturnAuthorization
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rma, err = lockReturn(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if rma.Status != models.ReturnStatusReceiving {
+			return fmt.Errorf("%w: cannot dispose units of a %s return", ErrInvalidTransition, rma.Status)
+		}
+
+		info.Reason = models.MovementReasonReturn
+		info = withReference(info, fmt.Sprintf("rma:%s", rma.Reference))
+		for _, disposition := range dispositions {
+			line := returnLine(rma, disposition.SKUID)
+			if line == nil {
+				return fmt.Errorf("sku %s is not on return %s", disposition.SKUCode, rma.Reference)
+			}
+			if disposition.Quantity > line.Undisposed() {
+				return fmt.Errorf("%w: sku %s has %d units waiting, disposing %d", ErrQuantityExceeded, disposition.SKUCode, line.Undisposed(), disposition.Quantity)
+			}
+			if err := checkReturnSerials(tx, disposition); err != nil {
+				return err
+			}
+
+			movement, column, err := applyReturnDisposition(tx, rma, disposition, info)
+			if err != nil {
+				return fmt.Errorf("sku %s: %w", disposition.SKUCode, err)
+			}
+
+			if err := tx.Model(line).Update(column, gorm.Expr(column+" + ?", disposition.Quantity)).Error; err != nil {
+				return fmt.Errorf("error when updating return line %v", err)
+			}
+
+			event := models.ReturnEvent{
+				Action:      models.ReturnEventDisposed,
+				SKUCode:     disposition.SKUCode,
+				Disposition: disposition.Disposition,
+				Quantity:    disposition.Quantity,
+				Serials:     disposition.Serials,
+				Actor:       info.Actor,
+				Note:        disposition.Note,
+			}
+			if movement != nil {
+				event.MovementID = &movement.ID
+			}
+			if err := recordReturnEvent(tx, rma, event); err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("return_id = ?", rma.ID).Order("id").Find(&rma.Lines).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when disposing returned units %v", err)
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+// Complete closes a return once every received unit has a disposition
+func (r *ReturnRepo) Complete(ctx context.Context, tenantID string, id int64, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnRepo][Complete]"
+	log.InfofWithContext(ctx, logTag+" completing return", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.ReturnStatusCompleted, models.ReturnEventCompleted, actor, func(rma *models.ReturnAuthorization) error {
+		if rma.Status != models.ReturnStatusReceiving {
+			return fmt.Errorf("%w: cannot complete a %s return", ErrInvalidTransition, rma.Status)
+		}
+		for _, line := range rma.Lines {
+			if line.Undisposed() > 0 {
+				return fmt.Errorf("%w: sku %s has %d units without a disposition", ErrInvalidTransition, line.SKUCode, line.Undisposed())
+			}
+		}
+		return nil
+	})
+}
+
+// Cancel withdraws a return nothing has arrived for
+func (r *ReturnRepo) Cancel(ctx context.Context, tenantID string, id int64, actor string) (*models.ReturnAuthorization, error) {
+	logTag := "[ReturnRepo][Cancel]"
+	log.InfofWithContext(ctx, logTag+" cancelling return", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.ReturnStatusCancelled, models.ReturnEventCancelled, actor, func(rma *models.ReturnAuthorization) error {
+		if rma.Status != models.ReturnStatusAuthorized {
+			return fmt.Errorf("%w: cannot cancel a %s return", ErrInvalidTransition, rma.Status)
+		}
+		return nil
+	})
+}
+
+func (r *ReturnRepo) finish(ctx context.Context, logTag, tenantID string, id int64, status, action, actor string, check func(*models.ReturnAuthorization) error) (*models.ReturnAuthorization, error) {
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var rma *models.ReturnAuthorization
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		rma, err = lockReturn(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := check(rma); err != nil {
+			return err
+		}
+
+		rma.Status = status
+		if err := tx.Model(rma).Update("status", rma.Status).Error; err != nil {
+			return fmt.Errorf("error when updating return status %v", err)
+		}
+
+		return recordReturnEvent(tx, rma, models.ReturnEvent{
+			Action: action,
+			Actor:  actor,
+		})
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when finishing return %v", err)
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+// applyReturnDisposition posts the stock effect of a disposition and returns
+// the return line column that counts it
+func applyReturnDisposition(tx *gorm.DB, rma *models.ReturnAuthorization, disposition ReturnDisposition, info MovementInfo) (*models.InventoryMovement, string, error) {
+	change := stockChange{
+		TenantID:        rma.TenantID,
+		SellerID:        rma.SellerID,
+		HubID:           *rma.HubID,
+		SKUID:           disposition.SKUID,
+		CreateIfMissing: true,
+	}
+
+	switch disposition.Disposition {
+	case models.DispositionRestock:
+		change.Delta = disposition.Quantity
+		info.Serials = disposition.Serials
+		_, movement, err := applyStockDelta(tx, change, info)
+		return movement, "restocked_quantity", err
+	case models.DispositionDamaged:
+		// the sellable quantity stays as it is, the ledger row only records
+		// that damaged units arrived
+		info.UnsellableDelta = disposition.Quantity
+		inventory, movement, err := applyStockDelta(tx, change, info)
+		if err != nil {
+			return nil, "", err
+		}
+		column := models.InventoryStatusColumn[models.InventoryStatusDamaged]
+		if err := tx.Model(inventory).Updates(map[string]interface{}{
+			column:    gorm.Expr(column+" + ?", disposition.Quantity),
+			"version": gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return nil, "", fmt.Errorf("error when updating damaged bucket %v", err)
+		}
+		return movement, "damaged_quantity", nil
+	case models.DispositionDestroy:
+		return nil, "destroyed_quantity", nil
+	case models.DispositionReturnToSeller:
+		return nil, "returned_to_seller_quantity", nil
+	default:
+		return nil, "", fmt.Errorf("unknown disposition %s", disposition.Disposition)
+	}
+}
+
+// checkReturnSerials makes every disposition of a serialized sku name its
+// units, restocks are checked again by the ledger when the serials go back
+// into stock
+func checkReturnSerials(tx *gorm.DB, disposition ReturnDisposition) error {
+	var serialized bool
+	if err := tx.Model(&models.SKU{}).
+		Select("serialized").
+		Where("id = ?", disposition.SKUID).
+		Scan(&serialized).Error; err != nil {
+		return fmt.Errorf("error when checking sku %v", err)
+	}
+
+	switch {
+	case !serialized && len(disposition.Serials) > 0:
+		return fmt.Errorf("%w: sku %s is not serialized", ErrSerialRequired, disposition.SKUCode)
+	case serialized && int64(len(disposition.Serials)) != disposition.Quantity:
+		return fmt.Errorf("%w: sku %s disposes %d units with %d serials", ErrSerialRequired, disposition.SKUCode, disposition.Quantity, len(disposition.Serials))
+	}
+	return nil
+}
+
+func recordReturnEvent(tx *gorm.DB, rma *models.ReturnAuthorization, event models.ReturnEvent) error {
+	event.ReturnID = rma.ID
+	if event.Serials == nil {
+		event.Serials = []string{}
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("error when recording return event %v", err)
+	}
+	return nil
+}
+
+func lockReturn(tx *gorm.DB, tenantID string, id int64) (*models.ReturnAuthorization, error) {
+	var rma models.ReturnAuthorization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&rma).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, fmt.Errorf("error when locking return %v", err)
+	}
+
+	if err := tx.Where("return_id = ?", id).Order("id").Find(&rma.Lines).Error; err != nil {
+		return nil, fmt.Errorf("error when getting return lines %v", err)
+	}
+
+	return &rma, nil
+}
+
+func returnLine(rma *models.ReturnAuthorization, skuID int) *models.ReturnLine {
+	for i := range rma.Lines {
+		if rma.Lines[i].SKUID == skuID {
+			return &rma.Lines[i]
+		}
+	}
+	return nil
+}
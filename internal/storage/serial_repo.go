@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SerialRepo struct {
+	DB *Postgres
+}
+
+func NewSerialRepo(db *Postgres) *SerialRepo {
+	return &SerialRepo{
+		DB: db,
+	}
+}
+
+// GetBySerialNumber returns every serial of the tenant with that number, the
+// same number may exist once per sku
+func (r *SerialRepo) GetBySerialNumber(ctx context.Context, tenantID, serialNumber string) ([]models.SerialHistory, error) {
+	logTag := "[SerialRepo][GetBySerialNumber]"
+	log.InfofWithContext(ctx, logTag+" getting serial from db", "tenant_id", tenantID, "serial_number", serialNumber)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var serials []models.SerialHistory
+	if err := db.Table("serials AS sr").
+		Select("sr.*, s.sku_code AS sku").
+		Joins("JOIN skus AS s ON s.id = sr.sku_id").
+		Where("sr.tenant_id = ? AND sr.serial_number = ?", tenantID, serialNumber).
+		Order("sr.id").
+		Scan(&serials).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting serial %v", err)
+		return nil, fmt.Errorf("error when getting serial %v", err)
+	}
+
+	if len(serials) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSerialNotFound, serialNumber)
+	}
+
+	for i := range serials {
+		if err := db.Where("serial_id = ?", serials[i].ID).
+			Order("created_at, id").
+			Find(&serials[i].Events).Error; err != nil {
+			log.ErrorfWithContext(ctx, logTag+" error when getting serial events %v", err)
+			return nil, fmt.Errorf("error when getting serial events %v", err)
+		}
+	}
+
+	return serials, nil
+}
+
+// applySerials moves the serials listed on a change along with the ledger
+// movement, serialized skus must name exactly one serial per unit moved and
+// other skus must name none
+func applySerials(tx *gorm.DB, inventory *models.Inventory, movement *models.InventoryMovement, serials []string) error {
+	var serialized bool
+	if err := tx.Model(&models.SKU{}).
+		Select("serialized").
+		Where("id = ?", inventory.SKUID).
+		Scan(&serialized).Error; err != nil {
+		return fmt.Errorf("error when checking sku %v", err)
+	}
+
+	units := movement.Delta
+	if units < 0 {
+		units = -units
+	}
+
+	switch {
+	case !serialized && len(serials) > 0:
+		return fmt.Errorf("%w: sku %d is not serialized", ErrSerialRequired, inventory.SKUID)
+	case !serialized:
+		return nil
+	case int64(len(serials)) != units:
+		return fmt.Errorf("%w: %d units moved, %d serials given", ErrSerialRequired, units, len(serials))
+	}
+
+	seen := make(map[string]bool, len(serials))
+	for _, number := range serials {
+		if seen[number] {
+			return fmt.Errorf("%w: serial %s given twice", ErrSerialRequired, number)
+		}
+		seen[number] = true
+	}
+
+	status := models.SerialStatusInStock
+	if movement.Delta < 0 {
+		status = serialStatusFor(movement.Reason)
+	}
+
+	for _, number := range serials {
+		serial := models.Serial{
+			TenantID:     inventory.TenantID,
+			SellerID:     inventory.SellerID,
+			SKUID:        inventory.SKUID,
+			SerialNumber: number,
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sku_id = ? AND serial_number = ?", inventory.SKUID, number).
+			First(&serial).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if movement.Delta < 0 {
+				return fmt.Errorf("%w: serial %s is not in stock at hub %d", ErrSerialConflict, number, inventory.HubID)
+			}
+		case err != nil:
+			return fmt.Errorf("error when locking serial %v", err)
+		case movement.Delta > 0 && serial.Status == models.SerialStatusInStock:
+			return fmt.Errorf("%w: serial %s is already in stock at hub %d", ErrSerialConflict, number, serial.HubID)
+		case movement.Delta < 0 && (serial.Status != models.SerialStatusInStock || serial.HubID != inventory.HubID):
+			return fmt.Errorf("%w: serial %s is not in stock at hub %d", ErrSerialConflict, number, inventory.HubID)
+		}
+
+		serial.HubID = inventory.HubID
+		serial.Status = status
+		if err := tx.Save(&serial).Error; err != nil {
+			return fmt.Errorf("error when saving serial %v", err)
+		}
+
+		if err := tx.Create(&models.SerialEvent{
+			SerialID:   serial.ID,
+			HubID:      inventory.HubID,
+			Status:     status,
+			MovementID: movement.ID,
+			Reason:     movement.Reason,
+			Actor:      movement.Actor,
+			Reference:  movement.Reference,
+		}).Error; err != nil {
+			return fmt.Errorf("error when recording serial event %v", err)
+		}
+	}
+
+	return nil
+}
+
+// inTransitSerials narrows serials down to the ones still in transit
+func inTransitSerials(tx *gorm.DB, skuID int, serials []string) ([]string, error) {
+	if len(serials) == 0 {
+		return nil, nil
+	}
+
+	var inTransit []string
+	if err := tx.Model(&models.Serial{}).
+		Where("sku_id = ? AND serial_number IN ? AND status = ?", skuID, serials, models.SerialStatusInTransit).
+		Order("serial_number").
+		Pluck("serial_number", &inTransit).Error; err != nil {
+		return nil, fmt.Errorf("error when getting serials in transit %v", err)
+	}
+	return inTransit, nil
+}
+
+// serialStatusFor is the status a serial takes when it leaves a hub for reason
+func serialStatusFor(reason string) string {
+	switch reason {
+	case models.MovementReasonTransferOut:
+		return models.SerialStatusInTransit
+	case models.MovementReasonSale, models.MovementReasonReservationCommit, models.MovementReasonPick:
+		return models.SerialStatusShipped
+	default:
+		return models.SerialStatusRemoved
+	}
+}
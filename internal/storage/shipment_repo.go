@@ -0,0 +1,419 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PackedItem is a scan of units of one sku into a carton, serialized skus
+// name every unit
+type PackedItem struct {
+	SKUID      int
+	SKUCode    string
+	Quantity   int64
+	Serials    []string
+	Serialized bool
+}
+
+// CartonMeasure is what a carton is sealed with
+type CartonMeasure struct {
+	LengthCm float64
+	WidthCm  float64
+	HeightCm float64
+	WeightKg float64
+}
+
+type ShipmentRepo struct {
+	DB *Postgres
+}
+
+func NewShipmentRepo(db *Postgres) *ShipmentRepo {
+	return &ShipmentRepo{
+		DB: db,
+	}
+}
+
+func (r *ShipmentRepo) Create(ctx context.Context, shipment *models.Shipment) error {
+	logTag := "[ShipmentRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating shipment in db", "order_ref", shipment.OrderRef, "hub_id", shipment.HubID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	shipment.Status = models.ShipmentStatusOpen
+	if err := db.Create(shipment).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating shipment %v", err)
+		return fmt.Errorf("error when creating shipment in db %v", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" shipment created successfully", "id", shipment.ID)
+	return nil
+}
+
+func (r *ShipmentRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.Shipment, error) {
+	logTag := "[ShipmentRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting shipment by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var shipment models.Shipment
+	if err := db.Preload("Cartons", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Cartons.Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&shipment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShipmentNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting shipment %v", err)
+		return nil, fmt.Errorf("error when getting shipment %v", err)
+	}
+
+	return &shipment, nil
+}
+
+// AddCarton opens a new carton on an open shipment, an empty code numbers it
+// after the cartons already there
+func (r *ShipmentRepo) AddCarton(ctx context.Context, tenantID string, shipmentID int64, code string) (*models.Carton, error) {
+	logTag := "[ShipmentRepo][AddCarton]"
+	log.InfofWithContext(ctx, logTag+" adding carton to shipment", "shipment_id", shipmentID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	carton := &models.Carton{
+		ShipmentID: shipmentID,
+		Code:       code,
+		Status:     models.CartonStatusOpen,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpenShipment(tx, tenantID, shipmentID); err != nil {
+			return err
+		}
+
+		var cartons int64
+		if err := tx.Model(&models.Carton{}).Where("shipment_id = ?", shipmentID).Count(&cartons).Error; err != nil {
+			return fmt.Errorf("error when counting cartons %v", err)
+		}
+		if carton.Code == "" {
+			carton.Code = fmt.Sprintf("C%d", cartons+1)
+		}
+
+		if err := tx.Create(carton).Error; err != nil {
+			return fmt.Errorf("error when creating carton in db %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when adding carton %v", err)
+		return nil, err
+	}
+
+	return carton, nil
+}
+
+// PackItem scans units into an open carton. Only units picked for the order
+// at the shipment's hub can be packed, and each of them only once across the
+// order's shipments
+func (r *ShipmentRepo) PackItem(ctx context.Context, tenantID string, cartonID int64, item PackedItem) (*models.Carton, error) {
+	logTag := "[ShipmentRepo][PackItem]"
+	log.InfofWithContext(ctx, logTag+" packing item", "carton_id", cartonID, "sku", item.SKUCode, "quantity", item.Quantity)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var carton models.Carton
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shipment_id IN (SELECT id FROM shipments WHERE tenant_id = ?)", cartonID, tenantID).
+			First(&carton).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCartonNotFound
+			}
+			return fmt.Errorf("error when locking carton %v", err)
+		}
+		if carton.Status != models.CartonStatusOpen {
+			return fmt.Errorf("%w: carton %s is %s", ErrInvalidTransition, carton.Code, carton.Status)
+		}
+
+		shipment, err := lockOpenShipment(tx, tenantID, carton.ShipmentID)
+		if err != nil {
+			return err
+		}
+
+		// every shipment of the order packs against the same picked units, the
+		// allocation row serialises them
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", shipment.AllocationID).
+			First(&models.OrderAllocation{}).Error; err != nil {
+			return fmt.Errorf("error when locking order allocation %v", err)
+		}
+
+		var picked int64
+		if err := tx.Table("pick_tasks AS t").
+			Select("COALESCE(SUM(t.picked_quantity), 0)").
+			Joins("JOIN pick_waves AS w ON w.id = t.wave_id").
+			Where("t.allocation_id = ? AND t.sku_id = ? AND w.hub_id = ?", shipment.AllocationID, item.SKUID, shipment.HubID).
+			Scan(&picked).Error; err != nil {
+			return fmt.Errorf("error when summing picked units %v", err)
+		}
+
+		var packedItems []models.CartonItem
+		if err := tx.Table("carton_items AS i").
+			Select("i.*").
+			Joins("JOIN cartons AS c ON c.id = i.carton_id").
+			Joins("JOIN shipments AS s ON s.id = c.shipment_id").
+			Where("s.allocation_id = ? AND s.hub_id = ? AND s.status <> ? AND i.sku_id = ?",
+				shipment.AllocationID, shipment.HubID, models.ShipmentStatusCancelled, item.SKUID).
+			Scan(&packedItems).Error; err != nil {
+			return fmt.Errorf("error when getting packed units %v", err)
+		}
+
+		var packed int64
+		var packedSerials []string
+		for _, packedItem := range packedItems {
+			packed += packedItem.Quantity
+			packedSerials = append(packedSerials, packedItem.Serials...)
+		}
+		if packed+item.Quantity > picked {
+			return fmt.Errorf("%w: %d of sku %s picked, %d already packed, packing %d", ErrQuantityExceeded, picked, item.SKUCode, packed, item.Quantity)
+		}
+
+		if item.Serialized {
+			if err := checkPackedSerials(tx, shipment, item, packedSerials); err != nil {
+				return err
+			}
+		} else if len(item.Serials) > 0 {
+			return fmt.Errorf("%w: sku %s is not serialized", ErrSerialRequired, item.SKUCode)
+		}
+
+		row := models.CartonItem{
+			CartonID: carton.ID,
+			SKUID:    item.SKUID,
+			SKUCode:  item.SKUCode,
+		}
+		if err := tx.Where("carton_id = ? AND sku_id = ?", carton.ID, item.SKUID).
+			FirstOrCreate(&row).Error; err != nil {
+			return fmt.Errorf("error when getting carton item %v", err)
+		}
+
+		row.Quantity += item.Quantity
+		row.Serials = append(row.Serials, item.Serials...)
+		if err := tx.Model(&row).Updates(map[string]interface{}{
+			"quantity": row.Quantity,
+			"serials":  row.Serials,
+		}).Error; err != nil {
+			return fmt.Errorf("error when updating carton item %v", err)
+		}
+
+		return tx.Where("carton_id = ?", carton.ID).Order("id").Find(&carton.Items).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when packing item %v", err)
+		return nil, err
+	}
+
+	return &carton, nil
+}
+
+// SealCarton records the measured dimensions and weight of a packed carton,
+// a sealed carton takes no more items
+func (r *ShipmentRepo) SealCarton(ctx context.Context, tenantID string, cartonID int64, measure CartonMeasure) (*models.Carton, error) {
+	logTag := "[ShipmentRepo][SealCarton]"
+	log.InfofWithContext(ctx, logTag+" sealing carton", "carton_id", cartonID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var carton models.Carton
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND shipment_id IN (SELECT id FROM shipments WHERE tenant_id = ?)", cartonID, tenantID).
+			First(&carton).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCartonNotFound
+			}
+			return fmt.Errorf("error when locking carton %v", err)
+		}
+		if carton.Status != models.CartonStatusOpen {
+			return fmt.Errorf("%w: carton %s is already %s", ErrInvalidTransition, carton.Code, carton.Status)
+		}
+
+		if _, err := lockOpenShipment(tx, tenantID, carton.ShipmentID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("carton_id = ?", carton.ID).Order("id").Find(&carton.Items).Error; err != nil {
+			return fmt.Errorf("error when getting carton items %v", err)
+		}
+		if len(carton.Items) == 0 {
+			return fmt.Errorf("%w: carton %s is empty", ErrInvalidTransition, carton.Code)
+		}
+
+		carton.Status = models.CartonStatusSealed
+		carton.LengthCm = &measure.LengthCm
+		carton.WidthCm = &measure.WidthCm
+		carton.HeightCm = &measure.HeightCm
+		carton.WeightKg = &measure.WeightKg
+		return tx.Model(&carton).Updates(map[string]interface{}{
+			"status":    carton.Status,
+			"length_cm": carton.LengthCm,
+			"width_cm":  carton.WidthCm,
+			"height_cm": carton.HeightCm,
+			"weight_kg": carton.WeightKg,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when sealing carton %v", err)
+		return nil, err
+	}
+
+	return &carton, nil
+}
+
+// Close hands a shipment to its carrier, every carton has to be sealed
+func (r *ShipmentRepo) Close(ctx context.Context, tenantID string, id int64, trackingNumber string) (*models.Shipment, error) {
+	logTag := "[ShipmentRepo][Close]"
+	log.InfofWithContext(ctx, logTag+" closing shipment", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.ShipmentStatusClosed, func(tx *gorm.DB, shipment *models.Shipment) error {
+		var cartons []models.Carton
+		if err := tx.Where("shipment_id = ?", id).Find(&cartons).Error; err != nil {
+			return fmt.Errorf("error when getting cartons %v", err)
+		}
+		if len(cartons) == 0 {
+			return fmt.Errorf("%w: shipment has no cartons", ErrInvalidTransition)
+		}
+		for _, carton := range cartons {
+			if carton.Status != models.CartonStatusSealed {
+				return fmt.Errorf("%w: carton %s is not sealed", ErrInvalidTransition, carton.Code)
+			}
+		}
+
+		shipment.TrackingNumber = trackingNumber
+		return tx.Model(shipment).Update("tracking_number", trackingNumber).Error
+	})
+}
+
+// Cancel drops an open shipment, whatever was packed in it can be packed
+// into another shipment of the order
+func (r *ShipmentRepo) Cancel(ctx context.Context, tenantID string, id int64) (*models.Shipment, error) {
+	logTag := "[ShipmentRepo][Cancel]"
+	log.InfofWithContext(ctx, logTag+" cancelling shipment", "id", id)
+
+	return r.finish(ctx, logTag, tenantID, id, models.ShipmentStatusCancelled, func(*gorm.DB, *models.Shipment) error {
+		return nil
+	})
+}
+
+// GetManifestLines lists every packed sku of the shipments a hub closed for a
+// carrier between from and to
+func (r *ShipmentRepo) GetManifestLines(ctx context.Context, tenantID string, hubID int, carrier string, from, to time.Time) ([]models.ManifestLine, error) {
+	logTag := "[ShipmentRepo][GetManifestLines]"
+	log.InfofWithContext(ctx, logTag+" getting manifest lines", "hub_id", hubID, "carrier", carrier, "from", from)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var lines []models.ManifestLine
+	if err := db.Table("shipments AS s").
+		Select("s.id AS shipment_id, s.order_ref, s.tracking_number, c.code AS carton_code, c.length_cm, c.width_cm, c.height_cm, c.weight_kg, i.sku_code, k.name AS sku_name, i.quantity").
+		Joins("JOIN cartons AS c ON c.shipment_id = s.id").
+		Joins("JOIN carton_items AS i ON i.carton_id = c.id").
+		Joins("JOIN skus AS k ON k.id = i.sku_id").
+		Where("s.tenant_id = ? AND s.hub_id = ? AND s.carrier = ? AND s.status = ? AND s.closed_at >= ? AND s.closed_at < ?",
+			tenantID, hubID, carrier, models.ShipmentStatusClosed, from, to).
+		Order("s.id, c.code, i.sku_code").
+		Scan(&lines).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when getting manifest lines %v", err)
+		return nil, fmt.Errorf("error when getting manifest lines %v", err)
+	}
+
+	return lines, nil
+}
+
+func (r *ShipmentRepo) finish(ctx context.Context, logTag, tenantID string, id int64, status string, check func(*gorm.DB, *models.Shipment) error) (*models.Shipment, error) {
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var shipment *models.Shipment
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		shipment, err = lockOpenShipment(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := check(tx, shipment); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		shipment.Status = status
+		shipment.ClosedAt = &now
+		return tx.Model(shipment).Updates(map[string]interface{}{
+			"status":    shipment.Status,
+			"closed_at": shipment.ClosedAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when finishing shipment %v", err)
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+func lockOpenShipment(tx *gorm.DB, tenantID string, id int64) (*models.Shipment, error) {
+	var shipment models.Shipment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&shipment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("error when locking shipment %v", err)
+	}
+
+	if shipment.Status != models.ShipmentStatusOpen {
+		return nil, fmt.Errorf("%w: shipment is %s", ErrInvalidTransition, shipment.Status)
+	}
+	return &shipment, nil
+}
+
+// checkPackedSerials makes sure every scanned serial was picked at the hub and
+// is not in a carton already
+func checkPackedSerials(tx *gorm.DB, shipment *models.Shipment, item PackedItem, packedSerials []string) error {
+	if int64(len(item.Serials)) != item.Quantity {
+		return fmt.Errorf("%w: sku %s packs %d units with %d serials", ErrSerialRequired, item.SKUCode, item.Quantity, len(item.Serials))
+	}
+
+	var serials []models.Serial
+	if err := tx.Where("seller_id = ? AND sku_id = ? AND serial_number IN ?", shipment.SellerID, item.SKUID, item.Serials).
+		Find(&serials).Error; err != nil {
+		return fmt.Errorf("error when getting serials %v", err)
+	}
+
+	found := make(map[string]models.Serial, len(serials))
+	for _, serial := range serials {
+		found[serial.SerialNumber] = serial
+	}
+
+	seen := make(map[string]bool, len(item.Serials))
+	for _, number := range item.Serials {
+		serial, ok := found[number]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrSerialNotFound, number)
+		}
+		if seen[number] || slices.Contains(packedSerials, number) {
+			return fmt.Errorf("%w: %s is already packed", ErrSerialConflict, number)
+		}
+		if serial.HubID != shipment.HubID || serial.Status != models.SerialStatusShipped {
+			return fmt.Errorf("%w: %s was not picked at hub %d", ErrSerialConflict, number, shipment.HubID)
+		}
+		seen[number] = true
+	}
+	return nil
+}
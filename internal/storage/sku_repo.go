@@ -2,11 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/omniful/go_commons/log"
 	"github.com/singhJasvinder101/go_wms/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 
@@ -54,3 +57,131 @@ func (r *SKURepo) GetByCodes(ctx context.Context, tenantID, sellerID string, sku
 	return skus, nil
 }
 
+func (r *SKURepo) GetByID(ctx context.Context, id int) (*models.SKU, error) {
+	logTag := "[SKURepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" geting sku by id in database ", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var sku models.SKU
+	if err := db.Where("id = ?", id).First(&sku).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w with id %d", ErrSKUNotFound, id)
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting sku by id in db %v", err)
+		return nil, fmt.Errorf("error when getting sku by id in db %v", err)
+	}
+
+	return &sku, nil
+}
+
+// Update saves the name and metadata of a sku, its code and seller are fixed
+func (r *SKURepo) Update(ctx context.Context, sku *models.SKU) error {
+	logTag := "[SKURepo][Update]"
+	log.InfofWithContext(ctx, logTag+" updating sku in db", "sku", sku)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := db.Model(sku).Select("name", "metadata").Updates(sku).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when updating sku %v", err)
+		return fmt.Errorf("error when updating sku %v", err)
+	}
+
+	return nil
+}
+
+// SetArchived archives the sku at the given time, nil restores it
+func (r *SKURepo) SetArchived(ctx context.Context, sku *models.SKU, archivedAt *time.Time) error {
+	logTag := "[SKURepo][SetArchived]"
+	log.InfofWithContext(ctx, logTag+" setting sku archived_at in db", "id", sku.ID, "archived_at", archivedAt)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	if err := db.Model(sku).Update("archived_at", archivedAt).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when archiving sku %v", err)
+		return fmt.Errorf("error when archiving sku %v", err)
+	}
+
+	return nil
+}
+
+// skuOrderTables hold order lines that keep pointing at a sku after the
+// order is done, a sku on any of them cannot be deleted
+var skuOrderTables = []string{
+	"transfer_order_lines",
+	"cycle_count_lines",
+	"inbound_order_lines",
+	"order_allocation_lines",
+	"pick_tasks",
+	"carton_items",
+	"return_lines",
+}
+
+// Delete removes a sku that has no stock in any condition bucket at any hub,
+// no held reservations and appears on no order. Its empty inventory rows go
+// first, inventory no longer cascades from skus so stock can never be wiped
+// with the sku row
+func (r *SKURepo) Delete(ctx context.Context, sku *models.SKU) error {
+	logTag := "[SKURepo][Delete]"
+	log.InfofWithContext(ctx, logTag+" deleting sku", "id", sku.ID)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", sku.ID).First(sku).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrSKUNotFound, sku.SKUCode)
+			}
+			return fmt.Errorf("error when locking sku %v", err)
+		}
+
+		var stock struct {
+			Hubs  int64
+			Units int64
+		}
+		if err := tx.Table("inventory AS i").
+			Select("COUNT(*) AS hubs, COALESCE(SUM("+onHandExpr+"), 0) AS units").
+			Where("i.sku_id = ? AND (i.quantity <> 0 OR i.damaged_quantity <> 0 OR i.quarantine_quantity <> 0 OR i.expired_quantity <> 0)", sku.ID).
+			Scan(&stock).Error; err != nil {
+			return fmt.Errorf("error when summing sku stock %v", err)
+		}
+		if stock.Hubs > 0 {
+			return fmt.Errorf("%w: sku %s has %d units at %d hubs", ErrSKUInUse, sku.SKUCode, stock.Units, stock.Hubs)
+		}
+
+		var held int64
+		if err := tx.Model(&models.InventoryReservation{}).
+			Where("sku_id = ? AND status = ?", sku.ID, models.ReservationStatusHeld).
+			Count(&held).Error; err != nil {
+			return fmt.Errorf("error when counting reservations %v", err)
+		}
+		if held > 0 {
+			return fmt.Errorf("%w: sku %s has %d held reservations", ErrSKUInUse, sku.SKUCode, held)
+		}
+
+		for _, table := range skuOrderTables {
+			var lines int64
+			if err := tx.Table(table).Where("sku_id = ?", sku.ID).Count(&lines).Error; err != nil {
+				return fmt.Errorf("error when counting %s %v", table, err)
+			}
+			if lines > 0 {
+				return fmt.Errorf("%w: sku %s is on %d rows of %s", ErrSKUInUse, sku.SKUCode, lines, table)
+			}
+		}
+
+		if err := tx.Where("sku_id = ?", sku.ID).Delete(&models.Inventory{}).Error; err != nil {
+			return fmt.Errorf("error when deleting empty inventory rows %v", err)
+		}
+
+		if err := tx.Delete(sku).Error; err != nil {
+			return fmt.Errorf("error when deleting sku %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when deleting sku %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/omniful/go_commons/log"
+	"github.com/singhJasvinder101/go_wms/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferRepo struct {
+	DB *Postgres
+}
+
+func NewTransferRepo(db *Postgres) *TransferRepo {
+	return &TransferRepo{
+		DB: db,
+	}
+}
+
+func (r *TransferRepo) Create(ctx context.Context, transfer *models.TransferOrder) error {
+	logTag := "[TransferRepo][Create]"
+	log.InfofWithContext(ctx, logTag+" creating transfer order in db", "transfer", transfer)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	transfer.Status = models.TransferStatusCreated
+	if err := db.Create(transfer).Error; err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when creating transfer order in db %v", err)
+		return fmt.Errorf("error when creating transfer order in db %v", err)
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer order created successfully", "id", transfer.ID)
+	return nil
+}
+
+func (r *TransferRepo) GetByID(ctx context.Context, tenantID string, id int64) (*models.TransferOrder, error) {
+	logTag := "[TransferRepo][GetByID]"
+	log.InfofWithContext(ctx, logTag+" getting transfer order by id", "id", id)
+
+	db := r.DB.Cluster.GetSlaveDB(ctx)
+
+	var transfer models.TransferOrder
+	if err := db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("tenant_id = ? AND id = ?", tenantID, id).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		log.ErrorfWithContext(ctx, logTag+" error when getting transfer order %v", err)
+		return nil, fmt.Errorf("error when getting transfer order %v", err)
+	}
+
+	return &transfer, nil
+}
+
+// Dispatch takes every line out of the source hub in one transaction, the
+// units stay in transit on the lines until they are received
+func (r *TransferRepo) Dispatch(ctx context.Context, tenantID string, id int64, info MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferRepo][Dispatch]"
+	log.InfofWithContext(ctx, logTag+" dispatching transfer order", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var transfer *models.TransferOrder
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = lockTransfer(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if transfer.Status != models.TransferStatusCreated {
+			return fmt.Errorf("%w: cannot dispatch a %s transfer", ErrInvalidTransition, transfer.Status)
+		}
+
+		info.Reason = models.MovementReasonTransferOut
+		for i := range transfer.Lines {
+			line := &transfer.Lines[i]
+			lineInfo := transferInfo(info, transfer)
+			lineInfo.Serials = line.Serials
+			if _, _, err := applyStockDelta(tx, stockChange{
+				TenantID:            transfer.TenantID,
+				SellerID:            transfer.SellerID,
+				HubID:               transfer.SourceHubID,
+				SKUID:               line.SKUID,
+				Delta:               -line.Quantity,
+				RespectReservations: true,
+			}, lineInfo); err != nil {
+				return fmt.Errorf("sku %s: %w", line.SKUCode, err)
+			}
+
+			line.DispatchedQuantity = line.Quantity
+			line.InTransitQuantity = line.Quantity
+			if err := tx.Model(line).Update("dispatched_quantity", line.DispatchedQuantity).Error; err != nil {
+				return fmt.Errorf("error when updating transfer line %v", err)
+			}
+		}
+
+		now := time.Now()
+		transfer.Status = models.TransferStatusDispatched
+		transfer.DispatchedAt = &now
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"status":        transfer.Status,
+			"dispatched_at": transfer.DispatchedAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when dispatching transfer order %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer order dispatched successfully", "id", id)
+	return transfer, nil
+}
+
+// Receive books received units per sku id into the destination hub, a
+// transfer stays partially received until every dispatched unit arrives.
+// Serialized lines name the serials that arrived, they must be ones that were
+// dispatched on the line
+func (r *TransferRepo) Receive(ctx context.Context, tenantID string, id int64, received map[int]int64, serials map[int][]string, info MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferRepo][Receive]"
+	log.InfofWithContext(ctx, logTag+" receiving transfer order", "id", id, "received", received)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var transfer *models.TransferOrder
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = lockTransfer(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		if transfer.Status != models.TransferStatusDispatched && transfer.Status != models.TransferStatusPartiallyReceived {
+			return fmt.Errorf("%w: cannot receive a %s transfer", ErrInvalidTransition, transfer.Status)
+		}
+
+		info.Reason = models.MovementReasonTransferIn
+		complete := true
+		for i := range transfer.Lines {
+			line := &transfer.Lines[i]
+			quantity := received[line.SKUID]
+			delete(received, line.SKUID)
+
+			if quantity > line.InTransitQuantity {
+				return fmt.Errorf("%w: sku %s has %d in transit, received %d", ErrQuantityExceeded, line.SKUCode, line.InTransitQuantity, quantity)
+			}
+
+			if quantity > 0 {
+				dispatched := make(map[string]bool, len(line.Serials))
+				for _, number := range line.Serials {
+					dispatched[number] = true
+				}
+				for _, number := range serials[line.SKUID] {
+					if !dispatched[number] {
+						return fmt.Errorf("%w: serial %s was not dispatched on sku %s", ErrSerialConflict, number, line.SKUCode)
+					}
+				}
+
+				lineInfo := transferInfo(info, transfer)
+				lineInfo.Serials = serials[line.SKUID]
+				if _, _, err := applyStockDelta(tx, stockChange{
+					TenantID:        transfer.TenantID,
+					SellerID:        transfer.SellerID,
+					HubID:           transfer.DestinationHubID,
+					SKUID:           line.SKUID,
+					Delta:           quantity,
+					CreateIfMissing: true,
+				}, lineInfo); err != nil {
+					return fmt.Errorf("sku %s: %w", line.SKUCode, err)
+				}
+
+				line.ReceivedQuantity += quantity
+				line.InTransitQuantity -= quantity
+				if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+					return fmt.Errorf("error when updating transfer line %v", err)
+				}
+			}
+
+			if line.InTransitQuantity > 0 {
+				complete = false
+			}
+		}
+
+		if len(received) > 0 {
+			return fmt.Errorf("%w: received skus that are not on the transfer", ErrQuantityExceeded)
+		}
+
+		updates := map[string]interface{}{"status": models.TransferStatusPartiallyReceived}
+		if complete {
+			now := time.Now()
+			transfer.ReceivedAt = &now
+			updates["status"] = models.TransferStatusReceived
+			updates["received_at"] = transfer.ReceivedAt
+		}
+		transfer.Status = updates["status"].(string)
+		return tx.Model(transfer).Updates(updates).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when receiving transfer order %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer order received successfully", "id", id, "status", transfer.Status)
+	return transfer, nil
+}
+
+// Cancel voids the transfer, anything still in transit is put back into the
+// source hub
+func (r *TransferRepo) Cancel(ctx context.Context, tenantID string, id int64, info MovementInfo) (*models.TransferOrder, error) {
+	logTag := "[TransferRepo][Cancel]"
+	log.InfofWithContext(ctx, logTag+" cancelling transfer order", "id", id)
+
+	db := r.DB.Cluster.GetMasterDB(ctx)
+
+	var transfer *models.TransferOrder
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transfer, err = lockTransfer(tx, tenantID, id)
+		if err != nil {
+			return err
+		}
+
+		switch transfer.Status {
+		case models.TransferStatusCreated, models.TransferStatusDispatched, models.TransferStatusPartiallyReceived:
+		default:
+			return fmt.Errorf("%w: cannot cancel a %s transfer", ErrInvalidTransition, transfer.Status)
+		}
+
+		info.Reason = models.MovementReasonTransferCancel
+		for i := range transfer.Lines {
+			line := &transfer.Lines[i]
+			if line.InTransitQuantity == 0 {
+				continue
+			}
+
+			lineInfo := transferInfo(info, transfer)
+			lineInfo.Serials, err = inTransitSerials(tx, line.SKUID, line.Serials)
+			if err != nil {
+				return err
+			}
+
+			if _, _, err := applyStockDelta(tx, stockChange{
+				TenantID:        transfer.TenantID,
+				SellerID:        transfer.SellerID,
+				HubID:           transfer.SourceHubID,
+				SKUID:           line.SKUID,
+				Delta:           line.InTransitQuantity,
+				CreateIfMissing: true,
+			}, lineInfo); err != nil {
+				return fmt.Errorf("sku %s: %w", line.SKUCode, err)
+			}
+
+			// returned units are no longer in transit, keep dispatched in line
+			// with what actually left the source for good
+			line.DispatchedQuantity = line.ReceivedQuantity
+			line.InTransitQuantity = 0
+			if err := tx.Model(line).Update("dispatched_quantity", line.DispatchedQuantity).Error; err != nil {
+				return fmt.Errorf("error when updating transfer line %v", err)
+			}
+		}
+
+		now := time.Now()
+		transfer.Status = models.TransferStatusCancelled
+		transfer.CancelledAt = &now
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"status":       transfer.Status,
+			"cancelled_at": transfer.CancelledAt,
+		}).Error
+	})
+	if err != nil {
+		log.ErrorfWithContext(ctx, logTag+" error when cancelling transfer order %v", err)
+		return nil, err
+	}
+
+	log.InfofWithContext(ctx, logTag+" transfer order cancelled successfully", "id", id)
+	return transfer, nil
+}
+
+func lockTransfer(tx *gorm.DB, tenantID string, id int64) (*models.TransferOrder, error) {
+	var transfer models.TransferOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, id).
+		First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("error when locking transfer order %v", err)
+	}
+
+	if err := tx.Where("transfer_order_id = ?", id).Order("id").Find(&transfer.Lines).Error; err != nil {
+		return nil, fmt.Errorf("error when getting transfer lines %v", err)
+	}
+
+	return &transfer, nil
+}
+
+func transferInfo(info MovementInfo, transfer *models.TransferOrder) MovementInfo {
+	return withReference(info, fmt.Sprintf("transfer:%d", transfer.ID))
+}
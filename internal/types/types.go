@@ -1,6 +1,7 @@
 package types
 
 import (
+	"time"
 
 	"github.com/omniful/go_commons/db/sql/postgres"
 )
@@ -23,6 +24,49 @@ type AWSConfig struct {
 	}
 }
 
+type ReservationConfig struct {
+	DefaultTTL     time.Duration
+	ExpiryInterval time.Duration
+}
+
+type CycleCountConfig struct {
+	// RecountThresholdPct is the variance, in percent of the snapshot, above
+	// which a counted line has to be counted again
+	RecountThresholdPct int
+}
+
+type AllocationConfig struct {
+	// SplitPolicy is used when an order does not name one, one of none, line
+	// or unit
+	SplitPolicy string
+	// HoldTTL is how long allocated units stay reserved for an order, long
+	// enough for the order to be waved and picked
+	HoldTTL time.Duration
+}
+type IdempotencyConfig struct {
+	// TTL is how long a key and its stored response are kept for replays
+	TTL             time.Duration
+	CleanupInterval time.Duration
+	// PendingTimeout is how long a key may stay claimed by a request that
+	// has not answered before a retry takes it over
+	PendingTimeout time.Duration
+}
+
+type ImportConfig struct {
+	// MaxRows is the largest file, in data rows, an import accepts
+	MaxRows      int
+	PollInterval time.Duration
+	// LeaseTimeout is how long a job may stay running before another worker
+	// assumes its worker died and runs it again
+	LeaseTimeout time.Duration
+}
+
+type ReplenishmentConfig struct {
+	// WindowDays is how far back outbound movements are averaged over
+	WindowDays      int
+	LeadTimeDays    int
+	SafetyStockDays int
+}
 
 type AppConfig struct {
 	Environment string
@@ -32,4 +76,11 @@ type AppConfig struct {
 	RedisAddr   string
 	KafkaBroker string
 	AWSConfig   AWSConfig
+	Reservation ReservationConfig
+	CycleCount  CycleCountConfig
+	Allocation  AllocationConfig
+	Idempotency IdempotencyConfig
+	Import      ImportConfig
+
+	Replenishment ReplenishmentConfig
 }
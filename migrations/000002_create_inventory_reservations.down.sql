@@ -0,0 +1,4 @@
+drop index if exists idx_reservations_tenant_order;
+drop index if exists idx_reservations_sku_hub_status;
+
+drop table if exists inventory_reservations;
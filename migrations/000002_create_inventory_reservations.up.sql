@@ -0,0 +1,18 @@
+create table if not exists inventory_reservations (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    sku_id int not null references skus(id) on delete cascade,
+    order_ref text not null,
+    quantity bigint not null check (quantity > 0),
+    status text not null default 'held',
+    expires_at timestamp with time zone not null,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now()
+);
+
+create index if not exists idx_reservations_sku_hub_status on inventory_reservations(sku_id, hub_id, status, expires_at);
+create index if not exists idx_reservations_tenant_order on inventory_reservations(tenant_id, order_ref);
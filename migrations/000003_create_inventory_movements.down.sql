@@ -0,0 +1,3 @@
+drop index if exists idx_movements_sku_hub_created;
+
+drop table if exists inventory_movements;
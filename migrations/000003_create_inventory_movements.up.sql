@@ -0,0 +1,24 @@
+create table if not exists inventory_movements (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null,
+    sku_id int not null,
+    reason text not null,
+    actor text,
+    reference text,
+    delta bigint not null,
+    quantity_before bigint not null,
+    quantity_after bigint not null,
+
+    created_at timestamp with time zone default now()
+);
+
+create index if not exists idx_movements_sku_hub_created on inventory_movements(sku_id, hub_id, created_at);
+
+-- existing stock predates the ledger, seed it with an opening balance so the
+-- history of every row starts from a known quantity
+insert into inventory_movements (tenant_id, seller_id, hub_id, sku_id, reason, actor, delta, quantity_before, quantity_after, created_at)
+select tenant_id, seller_id, hub_id, sku_id, 'opening_balance', 'migration', quantity, 0, quantity, coalesce(updated_at, now())
+from inventory;
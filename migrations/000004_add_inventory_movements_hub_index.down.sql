@@ -0,0 +1 @@
+drop index if exists idx_movements_hub_seller_created;
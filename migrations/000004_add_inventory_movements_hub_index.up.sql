@@ -0,0 +1,2 @@
+-- point in time reads scan a hub's ledger up to a timestamp
+create index if not exists idx_movements_hub_seller_created on inventory_movements(hub_id, seller_id, created_at);
@@ -0,0 +1,4 @@
+drop table if exists transfer_order_lines;
+
+drop index if exists idx_transfer_orders_tenant_status;
+drop table if exists transfer_orders;
@@ -0,0 +1,33 @@
+create table if not exists transfer_orders (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    source_hub_id int not null references hubs(id),
+    destination_hub_id int not null references hubs(id),
+    reference text,
+    status text not null default 'created',
+    dispatched_at timestamp with time zone,
+    received_at timestamp with time zone,
+    cancelled_at timestamp with time zone,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    check (source_hub_id <> destination_hub_id)
+);
+
+create index if not exists idx_transfer_orders_tenant_status on transfer_orders(tenant_id, status);
+
+create table if not exists transfer_order_lines (
+    id bigserial primary key,
+
+    transfer_order_id bigint not null references transfer_orders(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    quantity bigint not null check (quantity > 0),
+    dispatched_quantity bigint not null default 0,
+    received_quantity bigint not null default 0,
+
+    unique(transfer_order_id, sku_id),
+    check (received_quantity <= dispatched_quantity)
+);
@@ -0,0 +1,8 @@
+alter table inventory_movements drop column if exists location_id;
+
+drop index if exists idx_bin_inventory_location;
+drop index if exists idx_bin_inventory_sku_hub;
+drop table if exists bin_inventory;
+
+drop index if exists idx_hub_locations_parent;
+drop table if exists hub_locations;
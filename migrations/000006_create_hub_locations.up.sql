@@ -0,0 +1,36 @@
+create table if not exists hub_locations (
+    id serial primary key,
+
+    hub_id int not null references hubs(id) on delete cascade,
+    parent_id int references hub_locations(id),
+    type text not null check (type in ('zone', 'aisle', 'rack', 'bin')),
+    code text not null,
+    name text,
+    capacity bigint check (capacity >= 0),
+    attributes jsonb default '{}',
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    unique(hub_id, code)
+);
+
+create index if not exists idx_hub_locations_parent on hub_locations(parent_id);
+
+create table if not exists bin_inventory (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    sku_id int not null references skus(id) on delete cascade,
+    location_id int not null references hub_locations(id),
+    quantity bigint not null default 0 check (quantity >= 0),
+
+    updated_at timestamp with time zone default now(),
+    unique(sku_id, location_id)
+);
+
+create index if not exists idx_bin_inventory_sku_hub on bin_inventory(sku_id, hub_id);
+create index if not exists idx_bin_inventory_location on bin_inventory(location_id);
+
+alter table inventory_movements add column if not exists location_id int;
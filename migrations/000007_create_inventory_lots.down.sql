@@ -0,0 +1,2 @@
+drop index if exists idx_inventory_lots_fefo;
+drop table if exists inventory_lots;
@@ -0,0 +1,19 @@
+create table if not exists inventory_lots (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    sku_id int not null references skus(id) on delete cascade,
+    lot_number text not null,
+    manufactured_at timestamp with time zone,
+    expires_at timestamp with time zone,
+    quantity bigint not null default 0 check (quantity >= 0),
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    unique(sku_id, hub_id, lot_number),
+    check (manufactured_at is null or expires_at is null or manufactured_at < expires_at)
+);
+
+create index if not exists idx_inventory_lots_fefo on inventory_lots(sku_id, hub_id, expires_at);
@@ -0,0 +1,10 @@
+alter table transfer_order_lines drop column if exists serials;
+
+drop index if exists idx_serial_events_serial;
+drop table if exists serial_events;
+
+drop index if exists idx_serials_sku_hub_status;
+drop index if exists idx_serials_tenant_number;
+drop table if exists serials;
+
+alter table skus drop column if exists serialized;
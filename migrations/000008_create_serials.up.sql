@@ -0,0 +1,37 @@
+alter table skus add column if not exists serialized boolean not null default false;
+
+create table if not exists serials (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    sku_id int not null references skus(id) on delete cascade,
+    serial_number text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    status text not null check (status in ('in_stock', 'in_transit', 'shipped', 'removed')),
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    unique(sku_id, serial_number)
+);
+
+create index if not exists idx_serials_tenant_number on serials(tenant_id, serial_number);
+create index if not exists idx_serials_sku_hub_status on serials(sku_id, hub_id, status);
+
+create table if not exists serial_events (
+    id bigserial primary key,
+
+    serial_id bigint not null references serials(id) on delete cascade,
+    hub_id int not null,
+    status text not null,
+    movement_id bigint not null references inventory_movements(id),
+    reason text not null,
+    actor text,
+    reference text,
+
+    created_at timestamp with time zone default now()
+);
+
+create index if not exists idx_serial_events_serial on serial_events(serial_id, created_at);
+
+alter table transfer_order_lines add column if not exists serials jsonb not null default '[]';
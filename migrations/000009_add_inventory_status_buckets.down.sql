@@ -0,0 +1,10 @@
+alter table inventory_movements
+    drop column if exists on_hand_after,
+    drop column if exists moved_quantity,
+    drop column if exists to_status,
+    drop column if exists from_status;
+
+alter table inventory
+    drop column if exists expired_quantity,
+    drop column if exists quarantine_quantity,
+    drop column if exists damaged_quantity;
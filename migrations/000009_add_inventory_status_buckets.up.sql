@@ -0,0 +1,15 @@
+alter table inventory
+    add column if not exists damaged_quantity bigint not null default 0 check (damaged_quantity >= 0),
+    add column if not exists quarantine_quantity bigint not null default 0 check (quarantine_quantity >= 0),
+    add column if not exists expired_quantity bigint not null default 0 check (expired_quantity >= 0);
+
+alter table inventory_movements
+    add column if not exists from_status text,
+    add column if not exists to_status text,
+    add column if not exists moved_quantity bigint not null default 0;
+
+-- quantity_after only counts sellable units from here on, ledger rows written
+-- before the buckets existed were all sellable
+alter table inventory_movements add column if not exists on_hand_after bigint;
+update inventory_movements set on_hand_after = quantity_after where on_hand_after is null;
+alter table inventory_movements alter column on_hand_after set not null;
@@ -0,0 +1,5 @@
+drop index if exists idx_cycle_count_lines_count;
+drop table if exists cycle_count_lines;
+
+drop index if exists idx_cycle_counts_hub_status;
+drop table if exists cycle_counts;
@@ -0,0 +1,34 @@
+create table if not exists cycle_counts (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    status text not null default 'open' check (status in ('open', 'recount_required', 'pending_approval', 'approved', 'cancelled')),
+    created_by text,
+    approved_by text,
+    approved_at timestamp with time zone,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now()
+);
+
+create index if not exists idx_cycle_counts_hub_status on cycle_counts(hub_id, status);
+
+create table if not exists cycle_count_lines (
+    id bigserial primary key,
+
+    cycle_count_id bigint not null references cycle_counts(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    location_id int references hub_locations(id),
+    snapshot_quantity bigint not null,
+    counted_quantity bigint check (counted_quantity >= 0),
+    variance bigint,
+    recount_required boolean not null default false,
+    recounts int not null default 0,
+    counted_by text,
+    counted_at timestamp with time zone
+);
+
+create index if not exists idx_cycle_count_lines_count on cycle_count_lines(cycle_count_id);
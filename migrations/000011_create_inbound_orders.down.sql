@@ -0,0 +1,4 @@
+drop table if exists inbound_order_lines;
+
+drop index if exists idx_inbound_orders_hub_status;
+drop table if exists inbound_orders;
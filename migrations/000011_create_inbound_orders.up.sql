@@ -0,0 +1,29 @@
+create table if not exists inbound_orders (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    type text not null check (type in ('purchase_order', 'asn')),
+    reference text not null,
+    status text not null default 'open' check (status in ('open', 'receiving', 'closed', 'cancelled')),
+    expected_at timestamp with time zone,
+    closed_at timestamp with time zone,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    unique(tenant_id, seller_id, reference)
+);
+
+create index if not exists idx_inbound_orders_hub_status on inbound_orders(hub_id, status);
+
+create table if not exists inbound_order_lines (
+    id bigserial primary key,
+
+    inbound_order_id bigint not null references inbound_orders(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    expected_quantity bigint not null default 0 check (expected_quantity >= 0),
+    received_quantity bigint not null default 0 check (received_quantity >= 0),
+    unique(inbound_order_id, sku_id)
+);
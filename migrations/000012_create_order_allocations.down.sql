@@ -0,0 +1,4 @@
+drop index if exists idx_order_allocation_lines_allocation;
+drop table if exists order_allocation_lines;
+
+drop table if exists order_allocations;
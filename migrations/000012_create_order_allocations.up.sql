@@ -0,0 +1,28 @@
+create table if not exists order_allocations (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    order_ref text not null,
+    split_policy text not null check (split_policy in ('none', 'line', 'unit')),
+    status text not null check (status in ('allocated', 'partial', 'unallocated')),
+    delivery_latitude double precision not null,
+    delivery_longitude double precision not null,
+
+    created_at timestamp with time zone default now(),
+    unique(tenant_id, seller_id, order_ref)
+);
+
+create table if not exists order_allocation_lines (
+    id bigserial primary key,
+
+    allocation_id bigint not null references order_allocations(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    hub_id int references hubs(id) on delete set null,
+    quantity bigint not null check (quantity > 0),
+    distance_km double precision,
+    reservation_id bigint references inventory_reservations(id) on delete set null
+);
+
+create index if not exists idx_order_allocation_lines_allocation on order_allocation_lines(allocation_id);
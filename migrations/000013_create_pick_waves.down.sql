@@ -0,0 +1,11 @@
+alter table order_allocation_lines drop column if exists short_quantity;
+alter table order_allocation_lines drop column if exists wave_id;
+
+drop index if exists idx_pick_tasks_pending_location;
+drop index if exists idx_pick_tasks_wave;
+drop table if exists pick_tasks;
+
+drop index if exists idx_pick_waves_hub_status;
+drop table if exists pick_waves;
+
+alter table hub_locations drop column if exists walk_sequence;
@@ -0,0 +1,41 @@
+alter table hub_locations add column if not exists walk_sequence int check (walk_sequence >= 0);
+
+create table if not exists pick_waves (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    status text not null default 'open' check (status in ('open', 'picking', 'completed', 'cancelled')),
+    completed_at timestamp with time zone,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now()
+);
+
+create index if not exists idx_pick_waves_hub_status on pick_waves(hub_id, status);
+
+create table if not exists pick_tasks (
+    id bigserial primary key,
+
+    wave_id bigint not null references pick_waves(id) on delete cascade,
+    allocation_id bigint not null references order_allocations(id) on delete cascade,
+    allocation_line_id bigint not null references order_allocation_lines(id) on delete cascade,
+    order_ref text not null,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    location_id int references hub_locations(id),
+    location_code text,
+    sequence int not null,
+    quantity bigint not null check (quantity > 0),
+    picked_quantity bigint not null default 0 check (picked_quantity >= 0),
+    short_quantity bigint not null default 0 check (short_quantity >= 0),
+    status text not null default 'pending' check (status in ('pending', 'picked', 'short')),
+    confirmed_by text,
+    confirmed_at timestamp with time zone
+);
+
+create index if not exists idx_pick_tasks_wave on pick_tasks(wave_id, sequence);
+create index if not exists idx_pick_tasks_pending_location on pick_tasks(location_id, sku_id) where status = 'pending';
+
+alter table order_allocation_lines add column if not exists wave_id bigint references pick_waves(id) on delete set null;
+alter table order_allocation_lines add column if not exists short_quantity bigint not null default 0 check (short_quantity >= 0);
@@ -0,0 +1,6 @@
+drop table if exists carton_items;
+drop table if exists cartons;
+
+drop index if exists idx_shipments_manifest;
+drop index if exists idx_shipments_allocation;
+drop table if exists shipments;
@@ -0,0 +1,45 @@
+create table if not exists shipments (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    hub_id int not null references hubs(id) on delete cascade,
+    allocation_id bigint not null references order_allocations(id) on delete cascade,
+    order_ref text not null,
+    carrier text not null,
+    tracking_number text,
+    status text not null default 'open' check (status in ('open', 'closed', 'cancelled')),
+    closed_at timestamp with time zone,
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now()
+);
+
+create index if not exists idx_shipments_allocation on shipments(allocation_id);
+create index if not exists idx_shipments_manifest on shipments(hub_id, carrier, closed_at) where status = 'closed';
+
+create table if not exists cartons (
+    id bigserial primary key,
+
+    shipment_id bigint not null references shipments(id) on delete cascade,
+    code text not null,
+    status text not null default 'open' check (status in ('open', 'sealed')),
+    length_cm double precision check (length_cm > 0),
+    width_cm double precision check (width_cm > 0),
+    height_cm double precision check (height_cm > 0),
+    weight_kg double precision check (weight_kg > 0),
+
+    created_at timestamp with time zone default now(),
+    unique(shipment_id, code)
+);
+
+create table if not exists carton_items (
+    id bigserial primary key,
+
+    carton_id bigint not null references cartons(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    quantity bigint not null default 0 check (quantity >= 0),
+    serials jsonb not null default '[]',
+    unique(carton_id, sku_id)
+);
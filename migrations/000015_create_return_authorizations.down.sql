@@ -0,0 +1,4 @@
+drop index if exists idx_return_events_return;
+drop table if exists return_events;
+drop table if exists return_lines;
+drop table if exists return_authorizations;
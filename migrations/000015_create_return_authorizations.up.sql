@@ -0,0 +1,50 @@
+create table if not exists return_authorizations (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    reference text not null,
+    order_ref text,
+    reason text,
+    hub_id int references hubs(id) on delete cascade,
+    status text not null default 'authorized' check (status in ('authorized', 'receiving', 'completed', 'cancelled')),
+
+    created_at timestamp with time zone default now(),
+    updated_at timestamp with time zone default now(),
+    unique(tenant_id, seller_id, reference)
+);
+
+create table if not exists return_lines (
+    id bigserial primary key,
+
+    return_id bigint not null references return_authorizations(id) on delete cascade,
+    sku_id int not null references skus(id),
+    sku_code text not null,
+    authorized_quantity bigint not null check (authorized_quantity > 0),
+    received_quantity bigint not null default 0 check (received_quantity >= 0),
+    restocked_quantity bigint not null default 0 check (restocked_quantity >= 0),
+    damaged_quantity bigint not null default 0 check (damaged_quantity >= 0),
+    destroyed_quantity bigint not null default 0 check (destroyed_quantity >= 0),
+    returned_to_seller_quantity bigint not null default 0 check (returned_to_seller_quantity >= 0),
+    unique(return_id, sku_id),
+    check (received_quantity <= authorized_quantity),
+    check (restocked_quantity + damaged_quantity + destroyed_quantity + returned_to_seller_quantity <= received_quantity)
+);
+
+create table if not exists return_events (
+    id bigserial primary key,
+
+    return_id bigint not null references return_authorizations(id) on delete cascade,
+    action text not null check (action in ('created', 'received', 'disposed', 'completed', 'cancelled')),
+    sku_code text,
+    disposition text check (disposition in ('restock', 'damaged', 'destroy', 'return_to_seller')),
+    quantity bigint not null default 0,
+    serials jsonb not null default '[]',
+    movement_id bigint references inventory_movements(id),
+    actor text,
+    note text,
+
+    created_at timestamp with time zone default now()
+);
+
+create index if not exists idx_return_events_return on return_events(return_id);
@@ -0,0 +1,19 @@
+create table if not exists idempotency_keys (
+    tenant_id text not null,
+    method text not null,
+    path text not null,
+    key text not null,
+
+    request_hash text not null,
+    status text not null default 'pending' check (status in ('pending', 'completed')),
+    response_code int,
+    response_headers jsonb not null default '{}',
+    response_body bytea,
+
+    created_at timestamp with time zone default now(),
+    expires_at timestamp with time zone not null,
+
+    primary key (tenant_id, method, path, key)
+);
+
+create index if not exists idx_idempotency_keys_expires on idempotency_keys(expires_at);
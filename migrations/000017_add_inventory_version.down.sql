@@ -0,0 +1 @@
+alter table inventory drop column if exists version;
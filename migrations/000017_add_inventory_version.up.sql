@@ -0,0 +1 @@
+alter table inventory add column if not exists version bigint not null default 1;
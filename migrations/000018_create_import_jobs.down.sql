@@ -0,0 +1,6 @@
+drop index if exists idx_import_job_errors_job;
+drop table if exists import_job_errors;
+
+drop index if exists idx_import_jobs_running;
+drop index if exists idx_import_jobs_pending;
+drop table if exists import_jobs;
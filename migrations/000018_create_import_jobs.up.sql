@@ -0,0 +1,34 @@
+create table if not exists import_jobs (
+    id bigserial primary key,
+
+    tenant_id text not null,
+    seller_id text not null,
+    type text not null check (type in ('sku')),
+    status text not null default 'pending' check (status in ('pending', 'running', 'completed', 'failed')),
+    file_name text,
+    content bytea not null,
+    actor text,
+    total_rows int not null default 0,
+    succeeded_rows int not null default 0,
+    failed_rows int not null default 0,
+    skipped_rows int not null default 0,
+    error text,
+
+    created_at timestamp with time zone default now(),
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone
+);
+
+create index if not exists idx_import_jobs_pending on import_jobs(id) where status = 'pending';
+create index if not exists idx_import_jobs_running on import_jobs(started_at) where status = 'running';
+
+create table if not exists import_job_errors (
+    id bigserial primary key,
+
+    job_id bigint not null references import_jobs(id) on delete cascade,
+    row_number int not null,
+    sku_code text,
+    message text not null
+);
+
+create index if not exists idx_import_job_errors_job on import_job_errors(job_id, row_number);
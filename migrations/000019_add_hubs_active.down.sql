@@ -0,0 +1,3 @@
+drop index if exists idx_hubs_tenant_active;
+
+alter table hubs drop column if exists active;
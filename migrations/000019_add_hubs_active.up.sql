@@ -0,0 +1,3 @@
+alter table hubs add column if not exists active boolean not null default true;
+
+create index if not exists idx_hubs_tenant_active on hubs(tenant_id) where active;
@@ -0,0 +1,4 @@
+alter table inventory drop constraint if exists inventory_hub_id_fkey;
+alter table inventory add constraint inventory_hub_id_fkey foreign key (hub_id) references hubs(id) on delete cascade;
+
+alter table hubs drop column if exists attributes;
@@ -0,0 +1,5 @@
+-- hubs carry free form attributes and cannot be deleted while inventory rows still point at them
+alter table hubs add column if not exists attributes jsonb default '{}';
+
+alter table inventory drop constraint if exists inventory_hub_id_fkey;
+alter table inventory add constraint inventory_hub_id_fkey foreign key (hub_id) references hubs(id) on delete restrict;
@@ -0,0 +1,4 @@
+alter table inventory drop constraint if exists inventory_sku_id_fkey;
+alter table inventory add constraint inventory_sku_id_fkey foreign key (sku_id) references skus(id) on delete cascade;
+
+alter table skus drop column if exists archived_at;
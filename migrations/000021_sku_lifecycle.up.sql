@@ -0,0 +1,5 @@
+-- skus can be archived and cannot be deleted while inventory rows still point at them
+alter table skus add column if not exists archived_at timestamp with time zone;
+
+alter table inventory drop constraint if exists inventory_sku_id_fkey;
+alter table inventory add constraint inventory_sku_id_fkey foreign key (sku_id) references skus(id) on delete restrict;
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// split policies decide how far an order may be broken up across hubs
+const (
+	// SplitPolicyNone ships the whole order from a single hub
+	SplitPolicyNone = "none"
+	// SplitPolicyLine lets every line come from its own hub
+	SplitPolicyLine = "line"
+	// SplitPolicyUnit lets the units of one line come from several hubs
+	SplitPolicyUnit = "unit"
+)
+
+const (
+	AllocationStatusAllocated   = "allocated"
+	AllocationStatusPartial     = "partial"
+	AllocationStatusUnallocated = "unallocated"
+)
+
+// OrderAllocation records which hubs fulfil an outbound order, every
+// allocated line holds its units with a reservation against the order
+type OrderAllocation struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID          string  `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID          string  `gorm:"type:text;not null" json:"seller_id"`
+	OrderRef          string  `gorm:"type:text;not null" json:"order_ref"`
+	SplitPolicy       string  `gorm:"type:text;not null" json:"split_policy"`
+	Status            string  `gorm:"type:text;not null" json:"status"`
+	DeliveryLatitude  float64 `gorm:"not null" json:"delivery_latitude"`
+	DeliveryLongitude float64 `gorm:"not null" json:"delivery_longitude"`
+
+	Lines []OrderAllocationLine `gorm:"foreignKey:AllocationID" json:"lines"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// OrderAllocationLine is the part of one sku that a hub ships, units no hub
+// could cover are kept on a line without a hub
+type OrderAllocationLine struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	AllocationID  int64    `gorm:"not null" json:"allocation_id"`
+	SKUID         int      `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode       string   `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	HubID         *int     `json:"hub_id"`
+	Quantity      int64    `gorm:"not null" json:"quantity"`
+	DistanceKm    *float64 `json:"distance_km"`
+	ReservationID *int64   `json:"reservation_id"`
+	// WaveID is the pick wave the line was batched into
+	WaveID *int64 `json:"wave_id"`
+	// ShortQuantity is what pickers could not find, it has to be sourced
+	// elsewhere
+	ShortQuantity int64 `gorm:"not null;default:0" json:"short_quantity"`
+}
+
+// HubAvailability is the available quantity of one sku at one hub
+type HubAvailability struct {
+	HubID     int   `json:"hub_id"`
+	SKUID     int   `gorm:"column:sku_id" json:"sku_id"`
+	Available int64 `json:"available"`
+}
+
+// NetworkAvailability is the available quantity of one sku at one active hub
+// of the tenant, as read for available to promise
+type NetworkAvailability struct {
+	HubID     int    `json:"hub_id"`
+	HubName   string `json:"hub_name"`
+	SKUID     int    `gorm:"column:sku_id" json:"sku_id"`
+	Available int64  `json:"available"`
+}
+
+// SKUAvailableToPromise is how many units of a sku the seller can sell across
+// the network, broken down by hub
+type SKUAvailableToPromise struct {
+	SKUID   int                   `json:"sku_id"`
+	SKUCode string                `json:"sku_code"`
+	Total   int64                 `json:"total"`
+	Hubs    []NetworkAvailability `json:"hubs"`
+}
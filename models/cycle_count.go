@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+const (
+	CycleCountStatusOpen            = "open"
+	CycleCountStatusRecountRequired = "recount_required"
+	CycleCountStatusPendingApproval = "pending_approval"
+	CycleCountStatusApproved        = "approved"
+	CycleCountStatusCancelled       = "cancelled"
+)
+
+// CycleCount is a physical count task for a hub, every line snapshots the
+// system quantity when the task is generated so variance is measured against
+// what the counter was asked to find
+type CycleCount struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID   string     `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID   string     `gorm:"type:text;not null" json:"seller_id"`
+	HubID      int        `gorm:"not null" json:"hub_id"`
+	Status     string     `gorm:"type:text;not null;default:open" json:"status"`
+	CreatedBy  string     `gorm:"type:text" json:"created_by"`
+	ApprovedBy string     `gorm:"type:text" json:"approved_by"`
+	ApprovedAt *time.Time `json:"approved_at"`
+
+	Lines []CycleCountLine `gorm:"foreignKey:CycleCountID" json:"lines"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CycleCountLine is one sku to count, either for the whole hub or, when
+// LocationID is set, for a single bin
+type CycleCountLine struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	CycleCountID     int64      `gorm:"not null" json:"cycle_count_id"`
+	SKUID            int        `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode          string     `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	LocationID       *int       `json:"location_id"`
+	SnapshotQuantity int64      `gorm:"not null" json:"snapshot_quantity"`
+	CountedQuantity  *int64     `json:"counted_quantity"`
+	Variance         *int64     `json:"variance"`
+	RecountRequired  bool       `gorm:"not null;default:false" json:"recount_required"`
+	Recounts         int        `gorm:"not null;default:0" json:"recounts"`
+	CountedBy        string     `gorm:"type:text" json:"counted_by"`
+	CountedAt        *time.Time `json:"counted_at"`
+}
+
+// VarianceAbove reports whether the counted quantity is off from the
+// snapshot by more than pct percent, any variance on an empty snapshot counts
+func (l CycleCountLine) VarianceAbove(pct int) bool {
+	if l.Variance == nil || *l.Variance == 0 {
+		return false
+	}
+	variance := *l.Variance
+	if variance < 0 {
+		variance = -variance
+	}
+	if l.SnapshotQuantity == 0 {
+		return true
+	}
+	return variance*100 > l.SnapshotQuantity*int64(pct)
+}
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestCycleCountLineVarianceAbove(t *testing.T) {
+	variance := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name     string
+		snapshot int64
+		variance *int64
+		pct      int
+		want     bool
+	}{
+		{name: "not counted yet", snapshot: 100, variance: nil, pct: 5, want: false},
+		{name: "no variance", snapshot: 100, variance: variance(0), pct: 0, want: false},
+		{name: "shortage under the threshold", snapshot: 100, variance: variance(-4), pct: 5, want: false},
+		{name: "shortage exactly at the threshold", snapshot: 100, variance: variance(-5), pct: 5, want: false},
+		{name: "shortage over the threshold", snapshot: 100, variance: variance(-6), pct: 5, want: true},
+		{name: "overage over the threshold", snapshot: 100, variance: variance(6), pct: 5, want: true},
+		{name: "zero threshold flags any variance", snapshot: 100, variance: variance(1), pct: 0, want: true},
+		{name: "small snapshot is not rounded away", snapshot: 3, variance: variance(1), pct: 30, want: true},
+		{name: "any variance on an empty snapshot", snapshot: 0, variance: variance(1), pct: 100, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := CycleCountLine{SnapshotQuantity: tt.snapshot, Variance: tt.variance}
+			if got := line.VarianceAbove(tt.pct); got != tt.want {
+				t.Errorf("VarianceAbove(%d) = %v, want %v", tt.pct, got, tt.want)
+			}
+		})
+	}
+}
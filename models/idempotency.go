@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	IdempotencyStatusPending   = "pending"
+	IdempotencyStatusCompleted = "completed"
+)
+
+// IdempotencyKey is a client supplied key along with the response of the
+// request that first used it, replays with the same key get that response
+// back instead of running the change again. A key only identifies a request
+// within one tenant, method and path
+type IdempotencyKey struct {
+	TenantID string `gorm:"primaryKey;type:text" json:"tenant_id"`
+	Method   string `gorm:"primaryKey;type:text" json:"method"`
+	Path     string `gorm:"primaryKey;type:text" json:"path"`
+	Key      string `gorm:"primaryKey;type:text" json:"key"`
+
+	// RequestHash is the sha256 of the request body, a key reused for a
+	// different request is rejected
+	RequestHash  string `gorm:"type:text;not null" json:"request_hash"`
+	Status       string `gorm:"type:text;not null;default:pending" json:"status"`
+	ResponseCode int    `json:"response_code"`
+	ResponseBody []byte `gorm:"type:bytea" json:"-"`
+	// ResponseHeaders keeps the headers a replay has to send again, such as
+	// the content type and etag
+	ResponseHeaders datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"-"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const (
+	ImportJobTypeSKU = "sku"
+)
+
+const (
+	ImportJobStatusPending   = "pending"
+	ImportJobStatusRunning   = "running"
+	ImportJobStatusCompleted = "completed"
+	ImportJobStatusFailed    = "failed"
+)
+
+// ImportJob is an uploaded file processed in the background, rows that fail
+// are recorded as errors and do not stop the rest of the file
+type ImportJob struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID string `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID string `gorm:"type:text;not null" json:"seller_id"`
+	Type     string `gorm:"type:text;not null" json:"type"`
+	Status   string `gorm:"type:text;not null;default:pending" json:"status"`
+	FileName string `gorm:"type:text" json:"file_name"`
+	Content  []byte `gorm:"type:bytea;not null" json:"-"`
+	Actor    string `gorm:"type:text" json:"actor"`
+
+	TotalRows     int `gorm:"not null;default:0" json:"total_rows"`
+	SucceededRows int `gorm:"not null;default:0" json:"succeeded_rows"`
+	FailedRows    int `gorm:"not null;default:0" json:"failed_rows"`
+	// SkippedRows named a sku the seller already has with the same details
+	// and nothing to book, they are neither imported nor rejected
+	SkippedRows int `gorm:"not null;default:0" json:"skipped_rows"`
+	// Error is set when the file as a whole could not be processed
+	Error string `gorm:"type:text" json:"error,omitempty"`
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// ImportJobError is a row of an import that was rejected, RowNumber counts
+// the header as row 1 so it matches what spreadsheets show
+type ImportJobError struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	JobID     int64  `gorm:"not null" json:"job_id"`
+	RowNumber int    `gorm:"not null" json:"row_number"`
+	SKUCode   string `gorm:"column:sku_code;type:text" json:"sku_code"`
+	Message   string `gorm:"type:text;not null" json:"message"`
+}
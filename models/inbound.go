@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+const (
+	InboundTypePurchaseOrder = "purchase_order"
+	InboundTypeASN           = "asn"
+)
+
+const (
+	InboundStatusOpen      = "open"
+	InboundStatusReceiving = "receiving"
+	InboundStatusClosed    = "closed"
+	InboundStatusCancelled = "cancelled"
+)
+
+const (
+	DiscrepancyMatched = "matched"
+	DiscrepancyShort   = "short"
+	DiscrepancyOver    = "over"
+)
+
+// InboundOrder is a purchase order or advance shipping notice telling a hub
+// what a seller expects to arrive, it takes receipts until it is closed
+type InboundOrder struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID   string     `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID   string     `gorm:"type:text;not null" json:"seller_id"`
+	HubID      int        `gorm:"not null" json:"hub_id"`
+	Type       string     `gorm:"type:text;not null" json:"type"`
+	Reference  string     `gorm:"type:text;not null" json:"reference"`
+	Status     string     `gorm:"type:text;not null;default:open" json:"status"`
+	ExpectedAt *time.Time `json:"expected_at"`
+	ClosedAt   *time.Time `json:"closed_at"`
+
+	Lines []InboundOrderLine `gorm:"foreignKey:InboundOrderID" json:"lines"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// InboundOrderLine is one expected sku, skus that arrive without being on the
+// order get a line with nothing expected
+type InboundOrderLine struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	InboundOrderID   int64  `gorm:"not null" json:"inbound_order_id"`
+	SKUID            int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode          string `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	ExpectedQuantity int64  `gorm:"not null;default:0" json:"expected_quantity"`
+	ReceivedQuantity int64  `gorm:"not null;default:0" json:"received_quantity"`
+}
+
+// InboundDiscrepancy compares what was expected with what was received for
+// one line of an inbound order
+type InboundDiscrepancy struct {
+	SKUCode  string `json:"sku_code"`
+	Expected int64  `json:"expected"`
+	Received int64  `json:"received"`
+	Variance int64  `json:"variance"`
+	Result   string `json:"result"`
+}
+
+// Discrepancies reports every line of the order as matched, short or over
+func (o InboundOrder) Discrepancies() []InboundDiscrepancy {
+	report := make([]InboundDiscrepancy, 0, len(o.Lines))
+	for _, line := range o.Lines {
+		d := InboundDiscrepancy{
+			SKUCode:  line.SKUCode,
+			Expected: line.ExpectedQuantity,
+			Received: line.ReceivedQuantity,
+			Variance: line.ReceivedQuantity - line.ExpectedQuantity,
+			Result:   DiscrepancyMatched,
+		}
+		switch {
+		case d.Variance < 0:
+			d.Result = DiscrepancyShort
+		case d.Variance > 0:
+			d.Result = DiscrepancyOver
+		}
+		report = append(report, d)
+	}
+	return report
+}
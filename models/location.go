@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	LocationTypeZone  = "zone"
+	LocationTypeAisle = "aisle"
+	LocationTypeRack  = "rack"
+	LocationTypeBin   = "bin"
+)
+
+// LocationParentType is the type a location's parent must have, zones sit
+// directly under the hub
+var LocationParentType = map[string]string{
+	LocationTypeZone:  "",
+	LocationTypeAisle: LocationTypeZone,
+	LocationTypeRack:  LocationTypeAisle,
+	LocationTypeBin:   LocationTypeRack,
+}
+
+// HubLocation is a node of the zone -> aisle -> rack -> bin tree of a hub
+type HubLocation struct {
+	ID int `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	HubID      int            `gorm:"not null" json:"hub_id"`
+	ParentID   *int           `json:"parent_id"`
+	Type       string         `gorm:"type:text;not null" json:"type"`
+	Code       string         `gorm:"type:text;not null" json:"code"`
+	Name       string         `gorm:"type:text" json:"name"`
+	Capacity   *int64         `json:"capacity"`
+	Attributes datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"attributes"`
+	// WalkSequence orders bins along the picking path, lower comes first
+	WalkSequence *int `json:"walk_sequence"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BinInventory is the part of an inventory row stored in a specific bin, the
+// hub level inventory.quantity stays the total across bins and unassigned stock
+type BinInventory struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID   string `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID   string `gorm:"type:text;not null" json:"seller_id"`
+	HubID      int    `gorm:"not null" json:"hub_id"`
+	SKUID      int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	LocationID int    `gorm:"not null" json:"location_id"`
+	Quantity   int64  `gorm:"not null;default:0;check:quantity>=0" json:"quantity"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (BinInventory) TableName() string {
+	return "bin_inventory"
+}
+
+// BinStock is a bin inventory row joined with its sku and location codes
+type BinStock struct {
+	SKU          string `json:"sku"`
+	LocationID   int    `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	Quantity     int64  `json:"quantity"`
+}
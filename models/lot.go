@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// InventoryLot is the part of an inventory row that came from one batch, the
+// rest of inventory.quantity is stock received without a lot
+type InventoryLot struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID       string     `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID       string     `gorm:"type:text;not null" json:"seller_id"`
+	HubID          int        `gorm:"not null" json:"hub_id"`
+	SKUID          int        `gorm:"column:sku_id;not null" json:"sku_id"`
+	LotNumber      string     `gorm:"type:text;not null" json:"lot_number"`
+	ManufacturedAt *time.Time `json:"manufactured_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	Quantity       int64      `gorm:"not null;default:0;check:quantity>=0" json:"quantity"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (InventoryLot) TableName() string {
+	return "inventory_lots"
+}
+
+// Expired reports whether the lot can no longer be allocated at now
+func (l InventoryLot) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(now)
+}
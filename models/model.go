@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 	"gorm.io/datatypes"
 )
@@ -11,10 +12,39 @@ type Hub struct {
 	TenantID  string         `gorm:"type:text;not null;index:idx_hubs_tenant" json:"tenant_id"`
 	Name      string         `gorm:"type:text;not null" json:"name"`
 	Location  datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"location"`
+	// Active hubs take part in network availability, inactive ones are
+	// skipped
+	Active    bool           `gorm:"not null;default:true" json:"active"`
+	// Attributes holds free form details such as opening hours or dock count
+	Attributes datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"attributes"`
 	
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 }
 
+// Coordinates reads the latitude and longitude out of the hub location, ok is
+// false when the hub was created without them
+func (h Hub) Coordinates() (lat, lng float64, ok bool) {
+	var location struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	if err := json.Unmarshal(h.Location, &location); err != nil {
+		return 0, 0, false
+	}
+	if location.Latitude == nil || location.Longitude == nil {
+		return 0, 0, false
+	}
+	return *location.Latitude, *location.Longitude, true
+}
+
+// NearbyHub is a hub of the tenant with its distance from a point
+type NearbyHub struct {
+	HubID      int            `json:"hub_id"`
+	Name       string         `json:"name"`
+	Location   datatypes.JSON `json:"location"`
+	DistanceKm float64        `json:"distance_km"`
+}
+
 type SKU struct {
 	ID        int            `gorm:"primaryKey;autoIncrement" json:"id"`
 
@@ -23,10 +53,32 @@ type SKU struct {
 	SKUCode   string         `gorm:"type:text;not null;" json:"sku_code"`
 	Name      string         `gorm:"type:text" json:"name"`
 	MetaData  datatypes.JSON `gorm:"column:metadata;type:jsonb;default:'{}'" json:"metadata"`
+	// Serialized skus track every unit by serial number
+	Serialized bool          `gorm:"not null;default:false" json:"serialized"`
+	// ArchivedAt is set once a sku is retired, it stays readable but takes
+	// no new stock
+	ArchivedAt *time.Time `json:"archived_at"`
 
 	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
 }
 
+const (
+	InventoryStatusSellable   = "sellable"
+	InventoryStatusDamaged    = "damaged"
+	InventoryStatusQuarantine = "quarantine"
+	InventoryStatusExpired    = "expired"
+)
+
+// InventoryStatusColumn is the inventory column holding each condition bucket
+var InventoryStatusColumn = map[string]string{
+	InventoryStatusSellable:   "quantity",
+	InventoryStatusDamaged:    "damaged_quantity",
+	InventoryStatusQuarantine: "quarantine_quantity",
+	InventoryStatusExpired:    "expired_quantity",
+}
+
+// Inventory is the stock of a sku at a hub, Quantity is the sellable bucket
+// and the only one reservations, lots, bins and the ledger work against
 type Inventory struct {
 	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
 
@@ -35,11 +87,90 @@ type Inventory struct {
 	HubID     int       `gorm:"not null;" json:"hub_id"`
 	SKUID     int       `gorm:"column:sku_id;not null;" json:"sku_id"`
 	Quantity  int64     `gorm:"not null;default:0;check:quantity>=0" json:"quantity"`
+
+	DamagedQuantity    int64 `gorm:"not null;default:0" json:"damaged_quantity"`
+	QuarantineQuantity int64 `gorm:"not null;default:0" json:"quarantine_quantity"`
+	ExpiredQuantity    int64 `gorm:"not null;default:0" json:"expired_quantity"`
+
+	// Version goes up on every change to the row, writers can send the one
+	// they read to have a stale write rejected
+	Version int64 `gorm:"not null;default:1" json:"version"`
 	
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// Bucket returns the units held in the given condition bucket
+func (i Inventory) Bucket(status string) int64 {
+	switch status {
+	case InventoryStatusDamaged:
+		return i.DamagedQuantity
+	case InventoryStatusQuarantine:
+		return i.QuarantineQuantity
+	case InventoryStatusExpired:
+		return i.ExpiredQuantity
+	default:
+		return i.Quantity
+	}
+}
+
+// OnHand is the physical stock of the row across every condition bucket
+func (i Inventory) OnHand() int64 {
+	return i.Quantity + i.DamagedQuantity + i.QuarantineQuantity + i.ExpiredQuantity
+}
+
 
 func (Inventory) TableName() string {
     return "inventory"
-}
\ No newline at end of file
+}
+
+// InventoryLevel is an inventory row along with the units currently held by
+// active reservations. OnHand counts every bucket, only sellable stock outside
+// reservations and expired lots is available
+type InventoryLevel struct {
+	Inventory
+
+	OnHand      int64 `json:"on_hand"`
+	Reserved    int64 `json:"reserved"`
+	ExpiredLots int64 `json:"expired_lots"`
+	Available   int64 `json:"available"`
+
+	Lots []InventoryLot `gorm:"-" json:"lots"`
+}
+
+// SKULevel is the per sku stock position at a hub
+type SKULevel struct {
+	SKUID       int    `gorm:"column:sku_id" json:"sku_id"`
+	SKU         string `json:"sku"`
+	OnHand      int64  `json:"on_hand"`
+	Sellable    int64  `json:"sellable"`
+	Damaged     int64  `json:"damaged"`
+	Quarantine  int64  `json:"quarantine"`
+	Expired     int64  `json:"expired"`
+	Reserved    int64  `json:"reserved"`
+	ExpiredLots int64  `json:"expired_lots"`
+	Available   int64  `json:"available"`
+	Version     int64  `json:"version"`
+
+	Lots []InventoryLot `gorm:"-" json:"lots"`
+}
+
+// InventoryExportRow is one inventory row as written to an export, with the
+// sku it holds
+type InventoryExportRow struct {
+	TenantID    string    `json:"tenant_id"`
+	SellerID    string    `json:"seller_id"`
+	HubID       int       `json:"hub_id"`
+	SKUID       int       `gorm:"column:sku_id" json:"sku_id"`
+	SKUCode     string    `gorm:"column:sku_code" json:"sku_code"`
+	SKUName     string    `gorm:"column:sku_name" json:"sku_name"`
+	OnHand      int64     `json:"on_hand"`
+	Sellable    int64     `json:"sellable"`
+	Damaged     int64     `json:"damaged"`
+	Quarantine  int64     `json:"quarantine"`
+	Expired     int64     `json:"expired"`
+	Reserved    int64     `json:"reserved"`
+	ExpiredLots int64     `json:"expired_lots"`
+	Available   int64     `json:"available"`
+	Version     int64     `json:"version"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+const (
+	MovementReasonOpeningBalance     = "opening_balance"
+	MovementReasonCreate             = "create"
+	MovementReasonUpsert             = "upsert"
+	MovementReasonAdjustment         = "adjustment"
+	MovementReasonSale               = "sale"
+	MovementReasonReturn             = "return"
+	MovementReasonCorrection         = "correction"
+	MovementReasonShrinkage          = "shrinkage"
+	MovementReasonReservationHold    = "reservation_hold"
+	MovementReasonReservationRelease = "reservation_release"
+	MovementReasonReservationCommit  = "reservation_commit"
+	MovementReasonTransferOut        = "transfer_out"
+	MovementReasonTransferIn         = "transfer_in"
+	MovementReasonTransferCancel     = "transfer_cancel"
+	MovementReasonBinAdjustment      = "bin_adjustment"
+	MovementReasonBinMove            = "bin_move"
+	MovementReasonLotReceipt         = "lot_receipt"
+	MovementReasonStatusChange       = "status_change"
+	MovementReasonCycleCount         = "cycle_count"
+	MovementReasonInboundReceipt     = "inbound_receipt"
+	MovementReasonPick               = "pick"
+)
+
+// InventoryMovement is an append only ledger entry written in the same
+// transaction as every change to an inventory row
+type InventoryMovement struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID       string `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID       string `gorm:"type:text;not null" json:"seller_id"`
+	HubID          int    `gorm:"not null" json:"hub_id"`
+	SKUID          int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	LocationID     *int   `json:"location_id"`
+	Reason         string `gorm:"type:text;not null" json:"reason"`
+	Actor          string `gorm:"type:text" json:"actor"`
+	Reference      string `gorm:"type:text" json:"reference"`
+	Delta          int64  `gorm:"not null" json:"delta"`
+	QuantityBefore int64  `gorm:"not null" json:"quantity_before"`
+	QuantityAfter  int64  `gorm:"not null" json:"quantity_after"`
+	// OnHandAfter counts every condition bucket, the quantities above only
+	// the sellable one
+	OnHandAfter int64 `gorm:"not null" json:"on_hand_after"`
+
+	// set only on status changes, the buckets the units left and entered
+	FromStatus    string `gorm:"type:text" json:"from_status,omitempty"`
+	ToStatus      string `gorm:"type:text" json:"to_status,omitempty"`
+	MovedQuantity int64  `gorm:"not null;default:0" json:"moved_quantity,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (InventoryMovement) TableName() string {
+	return "inventory_movements"
+}
+
+// SKUQuantitySnapshot is the on hand and sellable quantity of a sku as of a
+// point in time, taken from the last ledger entry at or before it
+type SKUQuantitySnapshot struct {
+	SKU            string     `json:"sku"`
+	Quantity       int64      `json:"quantity"`
+	Sellable       int64      `json:"sellable"`
+	LastMovementAt *time.Time `json:"last_movement_at"`
+}
+
+// AdjustmentResult is the outcome of one line of a batch adjustment
+type AdjustmentResult struct {
+	Line           int    `json:"line"`
+	HubID          int    `json:"hub_id"`
+	SellerID       string `json:"seller_id"`
+	SKUCode        string `json:"sku_code"`
+	Delta          int64  `json:"delta"`
+	QuantityBefore int64  `json:"quantity_before"`
+	QuantityAfter  int64  `json:"quantity_after"`
+	Version        int64  `json:"version"`
+	MovementID     int64  `json:"movement_id"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+const (
+	WaveStatusOpen      = "open"
+	WaveStatusPicking   = "picking"
+	WaveStatusCompleted = "completed"
+	WaveStatusCancelled = "cancelled"
+)
+
+const (
+	PickTaskStatusPending = "pending"
+	PickTaskStatusPicked  = "picked"
+	PickTaskStatusShort   = "short"
+)
+
+// PickWave batches allocated order lines of one hub so they are picked in a
+// single walk through the bins
+type PickWave struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID    string     `gorm:"type:text;not null" json:"tenant_id"`
+	HubID       int        `gorm:"not null" json:"hub_id"`
+	Status      string     `gorm:"type:text;not null;default:open" json:"status"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	Tasks []PickTask `gorm:"foreignKey:WaveID" json:"tasks"`
+	// Unreserved are lines whose hold lapsed and could not be reserved again,
+	// they stay out of the wave until stock is back
+	Unreserved []UnreservedLine `gorm:"-" json:"unreserved,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// UnreservedLine is an allocated order line left out of a wave because its
+// reservation expired and the hub no longer has the units to hold it again
+type UnreservedLine struct {
+	AllocationID     int64  `json:"allocation_id"`
+	AllocationLineID int64  `json:"allocation_line_id"`
+	OrderRef         string `json:"order_ref"`
+	SKUCode          string `json:"sku_code"`
+	Quantity         int64  `json:"quantity"`
+	Reason           string `json:"reason"`
+}
+
+// PickTask asks a picker to take a quantity of a sku out of one bin for one
+// order line, tasks without a location pick from unassigned stock. Sequence
+// is the position of the task on the walk through the hub
+type PickTask struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	WaveID           int64      `gorm:"not null" json:"wave_id"`
+	AllocationID     int64      `gorm:"not null" json:"allocation_id"`
+	AllocationLineID int64      `gorm:"not null" json:"allocation_line_id"`
+	OrderRef         string     `gorm:"type:text;not null" json:"order_ref"`
+	SKUID            int        `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode          string     `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	LocationID       *int       `json:"location_id"`
+	LocationCode     string     `gorm:"type:text" json:"location_code"`
+	Sequence         int        `gorm:"not null" json:"sequence"`
+	Quantity         int64      `gorm:"not null" json:"quantity"`
+	PickedQuantity   int64      `gorm:"not null;default:0" json:"picked_quantity"`
+	ShortQuantity    int64      `gorm:"not null;default:0" json:"short_quantity"`
+	Status           string     `gorm:"type:text;not null;default:pending" json:"status"`
+	ConfirmedBy      string     `gorm:"type:text" json:"confirmed_by"`
+	ConfirmedAt      *time.Time `json:"confirmed_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+const (
+	PutawayReasonConsolidate  = "consolidate"
+	PutawayReasonFreeCapacity = "free_capacity"
+)
+
+// PutawaySuggestion is a bin and how many of the arriving units to put there
+type PutawaySuggestion struct {
+	LocationID   int    `json:"location_id"`
+	LocationCode string `json:"location_code"`
+	ZoneCode     string `json:"zone_code"`
+	Quantity     int64  `json:"quantity"`
+	SKUQuantity  int64  `json:"sku_quantity"`
+	FreeCapacity *int64 `json:"free_capacity"`
+	Reason       string `json:"reason"`
+}
+
+// PutawayPlan spreads an arriving quantity over bins, whatever no eligible bin
+// has room for is left unplaced
+type PutawayPlan struct {
+	SKU         string              `json:"sku"`
+	HubID       int                 `json:"hub_id"`
+	Quantity    int64               `json:"quantity"`
+	Suggestions []PutawaySuggestion `json:"suggestions"`
+	Unplaced    int64               `json:"unplaced"`
+}
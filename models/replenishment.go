@@ -0,0 +1,29 @@
+package models
+
+// ReplenishmentLine is the demand of a sku at a hub over the velocity window
+// and what it takes to cover lead time plus safety stock from today's
+// quantity
+type ReplenishmentLine struct {
+	HubID         int    `json:"hub_id"`
+	SellerID      string `json:"seller_id"`
+	SKUID         int    `gorm:"column:sku_id" json:"sku_id"`
+	SKUCode       string `gorm:"column:sku_code" json:"sku_code"`
+	SKUName       string `gorm:"column:sku_name" json:"sku_name"`
+	Quantity      int64  `json:"quantity"`
+	OutboundUnits int64  `json:"outbound_units"`
+
+	AvgDailyOutbound float64 `gorm:"-" json:"avg_daily_outbound"`
+	// DaysOfCover is nil when the sku had no outbound in the window
+	DaysOfCover       *float64 `gorm:"-" json:"days_of_cover"`
+	TargetQuantity    int64    `gorm:"-" json:"target_quantity"`
+	SuggestedQuantity int64    `gorm:"-" json:"suggested_quantity"`
+}
+
+// ReplenishmentReport is the replenishment plan for the lines of a tenant
+// under the window and cover it was computed with
+type ReplenishmentReport struct {
+	WindowDays      int                 `json:"window_days"`
+	LeadTimeDays    int                 `json:"lead_time_days"`
+	SafetyStockDays int                 `json:"safety_stock_days"`
+	Lines           []ReplenishmentLine `json:"lines"`
+}
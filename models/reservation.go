@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	ReservationStatusHeld      = "held"
+	ReservationStatusReleased  = "released"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusExpired   = "expired"
+)
+
+// InventoryReservation earmarks units of a sku at a hub against an order
+// without touching inventory.quantity until it is committed
+type InventoryReservation struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID  string    `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID  string    `gorm:"type:text;not null" json:"seller_id"`
+	HubID     int       `gorm:"not null" json:"hub_id"`
+	SKUID     int       `gorm:"column:sku_id;not null" json:"sku_id"`
+	OrderRef  string    `gorm:"type:text;not null" json:"order_ref"`
+	Quantity  int64     `gorm:"not null;check:quantity>0" json:"quantity"`
+	Status    string    `gorm:"type:text;not null;default:held" json:"status"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (InventoryReservation) TableName() string {
+	return "inventory_reservations"
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	ReturnStatusAuthorized = "authorized"
+	ReturnStatusReceiving  = "receiving"
+	ReturnStatusCompleted  = "completed"
+	ReturnStatusCancelled  = "cancelled"
+)
+
+// dispositions decide what happens to a returned unit once it is inspected
+const (
+	// DispositionRestock puts the unit back into sellable stock
+	DispositionRestock = "restock"
+	// DispositionDamaged keeps the unit in the damaged bucket of the hub
+	DispositionDamaged = "damaged"
+	// DispositionDestroy writes the unit off, it never enters inventory
+	DispositionDestroy = "destroy"
+	// DispositionReturnToSeller sends the unit back to the seller
+	DispositionReturnToSeller = "return_to_seller"
+)
+
+const (
+	ReturnEventCreated   = "created"
+	ReturnEventReceived  = "received"
+	ReturnEventDisposed  = "disposed"
+	ReturnEventCompleted = "completed"
+	ReturnEventCancelled = "cancelled"
+)
+
+// ReturnAuthorization is a customer return (RMA) a seller has agreed to take
+// back, it is received at one hub and every received unit gets a disposition
+type ReturnAuthorization struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID  string `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID  string `gorm:"type:text;not null" json:"seller_id"`
+	Reference string `gorm:"type:text;not null" json:"reference"`
+	OrderRef  string `gorm:"type:text" json:"order_ref"`
+	Reason    string `gorm:"type:text" json:"reason"`
+	// HubID is set by the first receipt, the rest has to arrive there too
+	HubID  *int   `json:"hub_id"`
+	Status string `gorm:"type:text;not null;default:authorized" json:"status"`
+
+	Lines  []ReturnLine  `gorm:"foreignKey:ReturnID" json:"lines"`
+	Events []ReturnEvent `gorm:"foreignKey:ReturnID" json:"events"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ReturnLine is one authorized sku with how many units arrived and where
+// each of them went
+type ReturnLine struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	ReturnID                 int64  `gorm:"not null" json:"return_id"`
+	SKUID                    int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode                  string `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	AuthorizedQuantity       int64  `gorm:"not null" json:"authorized_quantity"`
+	ReceivedQuantity         int64  `gorm:"not null;default:0" json:"received_quantity"`
+	RestockedQuantity        int64  `gorm:"not null;default:0" json:"restocked_quantity"`
+	DamagedQuantity          int64  `gorm:"not null;default:0" json:"damaged_quantity"`
+	DestroyedQuantity        int64  `gorm:"not null;default:0" json:"destroyed_quantity"`
+	ReturnedToSellerQuantity int64  `gorm:"not null;default:0" json:"returned_to_seller_quantity"`
+}
+
+// Undisposed is how many received units still wait for a disposition
+func (l ReturnLine) Undisposed() int64 {
+	return l.ReceivedQuantity - l.RestockedQuantity - l.DamagedQuantity - l.DestroyedQuantity - l.ReturnedToSellerQuantity
+}
+
+// ReturnEvent is one entry of the audit trail of a return, stock effects
+// point at their ledger movement
+type ReturnEvent struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	ReturnID    int64                       `gorm:"not null" json:"return_id"`
+	Action      string                      `gorm:"type:text;not null" json:"action"`
+	SKUCode     string                      `gorm:"column:sku_code;type:text" json:"sku_code,omitempty"`
+	Disposition string                      `gorm:"type:text" json:"disposition,omitempty"`
+	Quantity    int64                       `gorm:"not null;default:0" json:"quantity"`
+	Serials     datatypes.JSONSlice[string] `gorm:"type:jsonb;default:'[]'" json:"serials"`
+	MovementID  *int64                      `json:"movement_id"`
+	Actor       string                      `gorm:"type:text" json:"actor"`
+	Note        string                      `gorm:"type:text" json:"note,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
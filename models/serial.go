@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+const (
+	SerialStatusInStock   = "in_stock"
+	SerialStatusInTransit = "in_transit"
+	SerialStatusShipped   = "shipped"
+	SerialStatusRemoved   = "removed"
+)
+
+// Serial is one physical unit of a serialized sku, HubID is the hub it is in
+// or, once it has left, the hub it was last in
+type Serial struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID     string `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID     string `gorm:"type:text;not null" json:"seller_id"`
+	SKUID        int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	SerialNumber string `gorm:"type:text;not null" json:"serial_number"`
+	HubID        int    `gorm:"not null" json:"hub_id"`
+	Status       string `gorm:"type:text;not null" json:"status"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SerialEvent is one entry in the status history of a serial, it points at
+// the ledger movement that caused it
+type SerialEvent struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	SerialID   int64  `gorm:"not null" json:"serial_id"`
+	HubID      int    `gorm:"not null" json:"hub_id"`
+	Status     string `gorm:"type:text;not null" json:"status"`
+	MovementID int64  `gorm:"not null" json:"movement_id"`
+	Reason     string `gorm:"type:text;not null" json:"reason"`
+	Actor      string `gorm:"type:text" json:"actor"`
+	Reference  string `gorm:"type:text" json:"reference"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SerialHistory is a serial together with its sku code and every status it
+// went through, oldest first
+type SerialHistory struct {
+	Serial
+
+	SKU    string        `json:"sku"`
+	Events []SerialEvent `gorm:"-" json:"events"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	ShipmentStatusOpen      = "open"
+	ShipmentStatusClosed    = "closed"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+const (
+	CartonStatusOpen   = "open"
+	CartonStatusSealed = "sealed"
+)
+
+// Shipment is what a pack station hands to a carrier for one allocated order
+// at one hub, it is packed carton by carton until it is closed
+type Shipment struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID       string     `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID       string     `gorm:"type:text;not null" json:"seller_id"`
+	HubID          int        `gorm:"not null" json:"hub_id"`
+	AllocationID   int64      `gorm:"not null" json:"allocation_id"`
+	OrderRef       string     `gorm:"type:text;not null" json:"order_ref"`
+	Carrier        string     `gorm:"type:text;not null" json:"carrier"`
+	TrackingNumber string     `gorm:"type:text" json:"tracking_number"`
+	Status         string     `gorm:"type:text;not null;default:open" json:"status"`
+	ClosedAt       *time.Time `json:"closed_at"`
+
+	Cartons []Carton `gorm:"foreignKey:ShipmentID" json:"cartons"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Carton is one box of a shipment, it takes scanned items until it is sealed
+// with its dimensions and weight
+type Carton struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	ShipmentID int64    `gorm:"not null" json:"shipment_id"`
+	Code       string   `gorm:"type:text;not null" json:"code"`
+	Status     string   `gorm:"type:text;not null;default:open" json:"status"`
+	LengthCm   *float64 `json:"length_cm"`
+	WidthCm    *float64 `json:"width_cm"`
+	HeightCm   *float64 `json:"height_cm"`
+	WeightKg   *float64 `json:"weight_kg"`
+
+	Items []CartonItem `gorm:"foreignKey:CartonID" json:"items"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// CartonItem is the quantity of one sku packed in a carton
+type CartonItem struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	CartonID int64                       `gorm:"not null" json:"carton_id"`
+	SKUID    int                         `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode  string                      `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	Quantity int64                       `gorm:"not null" json:"quantity"`
+	Serials  datatypes.JSONSlice[string] `gorm:"type:jsonb;default:'[]'" json:"serials"`
+}
+
+// ManifestLine is one sku in one carton of a closed shipment
+type ManifestLine struct {
+	ShipmentID     int64    `json:"shipment_id"`
+	OrderRef       string   `json:"order_ref"`
+	TrackingNumber string   `json:"tracking_number"`
+	CartonCode     string   `json:"carton_code"`
+	LengthCm       *float64 `json:"length_cm"`
+	WidthCm        *float64 `json:"width_cm"`
+	HeightCm       *float64 `json:"height_cm"`
+	WeightKg       *float64 `json:"weight_kg"`
+	SKUCode        string   `gorm:"column:sku_code" json:"sku_code"`
+	SKUName        string   `gorm:"column:sku_name" json:"sku_name"`
+	Quantity       int64    `json:"quantity"`
+}
+
+// Manifest lists everything a hub handed to one carrier on one day
+type Manifest struct {
+	HubID     int            `json:"hub_id"`
+	HubName   string         `json:"hub_name"`
+	Carrier   string         `json:"carrier"`
+	Date      string         `json:"date"`
+	Shipments int            `json:"shipments"`
+	Cartons   int            `json:"cartons"`
+	Units     int64          `json:"units"`
+	WeightKg  float64        `json:"weight_kg"`
+	Lines     []ManifestLine `json:"lines"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	TransferStatusCreated           = "created"
+	TransferStatusDispatched        = "dispatched"
+	TransferStatusPartiallyReceived = "partially_received"
+	TransferStatusReceived          = "received"
+	TransferStatusCancelled         = "cancelled"
+)
+
+// TransferOrder moves stock of a seller from one hub to another, dispatched
+// units sit in transit on the lines until the destination receives them
+type TransferOrder struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TenantID         string     `gorm:"type:text;not null" json:"tenant_id"`
+	SellerID         string     `gorm:"type:text;not null" json:"seller_id"`
+	SourceHubID      int        `gorm:"not null" json:"source_hub_id"`
+	DestinationHubID int        `gorm:"not null" json:"destination_hub_id"`
+	Reference        string     `gorm:"type:text" json:"reference"`
+	Status           string     `gorm:"type:text;not null;default:created" json:"status"`
+	DispatchedAt     *time.Time `json:"dispatched_at"`
+	ReceivedAt       *time.Time `json:"received_at"`
+	CancelledAt      *time.Time `json:"cancelled_at"`
+
+	Lines []TransferOrderLine `gorm:"foreignKey:TransferOrderID" json:"lines"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type TransferOrderLine struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	TransferOrderID    int64  `gorm:"not null" json:"transfer_order_id"`
+	SKUID              int    `gorm:"column:sku_id;not null" json:"sku_id"`
+	SKUCode            string `gorm:"column:sku_code;type:text;not null" json:"sku_code"`
+	Quantity           int64  `gorm:"not null" json:"quantity"`
+	DispatchedQuantity int64  `gorm:"not null;default:0" json:"dispatched_quantity"`
+	ReceivedQuantity   int64  `gorm:"not null;default:0" json:"received_quantity"`
+	// Serials lists the units to ship when the sku is serialized
+	Serials datatypes.JSONSlice[string] `gorm:"type:jsonb;default:'[]'" json:"serials"`
+
+	InTransitQuantity int64 `gorm:"-" json:"in_transit_quantity"`
+}
+
+func (l *TransferOrderLine) AfterFind(tx *gorm.DB) error {
+	l.InTransitQuantity = l.DispatchedQuantity - l.ReceivedQuantity
+	return nil
+}